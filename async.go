@@ -0,0 +1,308 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// OverflowPolicy determines the behavior of an AsyncHandler when it receives
+// events while its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes HandleEvent wait until space is available in the
+	// queue.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest discards the events received while the queue is full.
+	OverflowDropNewest
+
+	// OverflowDropOldest discards the oldest event of the queue to make room
+	// for the events received while the queue is full.
+	OverflowDropOldest
+
+	// OverflowDropDebug discards debug events first: the event received if it
+	// is a debug event, or the oldest debug event of the queue. When the queue
+	// contains no debug events the policy falls back to OverflowDropOldest.
+	OverflowDropDebug
+)
+
+// DefaultAsyncQueueSize is the size of the queue used by an AsyncHandler when
+// none is specified.
+const DefaultAsyncQueueSize = 1024
+
+// AsyncStats is a snapshot of the counters maintained by an AsyncHandler.
+type AsyncStats struct {
+	Queued       int    // number of events waiting in the queue
+	Handled      uint64 // number of events passed to the handler
+	Dropped      uint64 // number of events discarded by the overflow policy
+	DroppedDebug uint64 // number of debug events included in Dropped
+}
+
+// AsyncHandler is an event handler which decouples event producers from the
+// handler it wraps. Events are cloned into a bounded queue and passed to the
+// handler from a background goroutine, so a slow output does not stall the
+// program.
+//
+// The Flush and Close methods must be used to wait for queued events to be
+// handled, typically when the program shuts down.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type AsyncHandler struct {
+	handler Handler
+	policy  OverflowPolicy
+
+	mutex    sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	queue    []*Event
+	head     int // index of the oldest event in the queue
+	size     int // number of events in the queue
+	pushed   uint64
+	done     uint64 // number of events removed from the queue and handled or dropped
+	stats    AsyncStats
+	closed   bool
+	progress chan struct{} // closed and replaced when done changes
+	exit     chan struct{} // closed when the background goroutine exits
+
+	closeOnce sync.Once // closes the wrapped handler
+}
+
+// NewAsyncHandler creates a new asynchronous handler which passes events to
+// handler, queuing up to size events and applying policy when the queue is
+// full. If size is zero or negative, DefaultAsyncQueueSize is used.
+func NewAsyncHandler(handler Handler, size int, policy OverflowPolicy) *AsyncHandler {
+	if size <= 0 {
+		size = DefaultAsyncQueueSize
+	}
+
+	h := &AsyncHandler{
+		handler:  handler,
+		policy:   policy,
+		queue:    make([]*Event, size),
+		progress: make(chan struct{}),
+		exit:     make(chan struct{}),
+	}
+	h.notEmpty.L = &h.mutex
+	h.notFull.L = &h.mutex
+
+	go h.run()
+	return h
+}
+
+// HandleEvent satisfies the Handler interface.
+func (h *AsyncHandler) HandleEvent(e *Event) {
+	// Events that are going to be dropped are discarded before being cloned,
+	// cloning is the most expensive part of queuing events.
+	h.mutex.Lock()
+	if h.dropNewest(e) {
+		h.drop(e)
+		h.mutex.Unlock()
+		return
+	}
+	h.mutex.Unlock()
+
+	c := e.Clone()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for !h.closed && h.size == len(h.queue) {
+		switch h.policy {
+		case OverflowDropNewest:
+			h.drop(c)
+			return
+
+		case OverflowDropOldest:
+			h.dropQueued(0)
+
+		case OverflowDropDebug:
+			if c.Debug {
+				h.drop(c)
+				return
+			}
+			i := h.indexDebug()
+			if i < 0 {
+				i = 0
+			}
+			h.dropQueued(i)
+
+		default:
+			h.notFull.Wait()
+		}
+	}
+
+	if h.closed {
+		h.drop(c)
+		return
+	}
+
+	h.queue[(h.head+h.size)%len(h.queue)] = c
+	h.size++
+	h.pushed++
+	h.notEmpty.Signal()
+}
+
+// Stats returns a snapshot of the handler's counters.
+func (h *AsyncHandler) Stats() AsyncStats {
+	h.mutex.Lock()
+	stats := h.stats
+	stats.Queued = h.size
+	h.mutex.Unlock()
+	return stats
+}
+
+// Flush waits until all events queued when the method was called have been
+// passed to the handler, or ctx is canceled, then flushes the handler.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	h.mutex.Lock()
+	target := h.pushed
+
+	for h.done < target {
+		progress := h.progress
+		h.mutex.Unlock()
+
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		h.mutex.Lock()
+	}
+
+	h.mutex.Unlock()
+	return FlushHandler(ctx, h.handler)
+}
+
+// Close stops the handler, waiting until all queued events have been passed to
+// the handler or ctx is canceled, then closes the handler. Events received
+// after Close was called are dropped.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	h.mutex.Lock()
+	h.closed = true
+	h.notEmpty.Broadcast()
+	h.notFull.Broadcast()
+	h.mutex.Unlock()
+
+	select {
+	case <-h.exit:
+		// The wrapped handler is closed once, by the first call to Close which
+		// saw the queue drained, which may not be the first call to Close if
+		// it timed out.
+		var err error
+		h.closeOnce.Do(func() { err = CloseHandler(ctx, h.handler) })
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.exit)
+	batch := make([]*Event, 0, len(h.queue))
+
+	for {
+		h.mutex.Lock()
+
+		for h.size == 0 && !h.closed {
+			h.notEmpty.Wait()
+		}
+
+		if h.size == 0 {
+			h.mutex.Unlock()
+			return
+		}
+
+		for h.size != 0 {
+			batch = append(batch, h.remove(0))
+		}
+
+		h.notFull.Broadcast()
+		h.mutex.Unlock()
+
+		for _, e := range batch {
+			h.handler.HandleEvent(e)
+		}
+
+		h.mutex.Lock()
+		h.stats.Handled += uint64(len(batch))
+		h.advance(len(batch))
+		h.mutex.Unlock()
+
+		for i := range batch {
+			batch[i] = nil
+		}
+		batch = batch[:0]
+	}
+}
+
+// dropNewest must be called with the mutex held, it returns true if e would be
+// dropped if it was queued now.
+func (h *AsyncHandler) dropNewest(e *Event) bool {
+	if h.closed {
+		return true
+	}
+	if h.size != len(h.queue) {
+		return false
+	}
+	switch h.policy {
+	case OverflowDropNewest:
+		return true
+	case OverflowDropDebug:
+		return e.Debug
+	default:
+		return false
+	}
+}
+
+// drop must be called with the mutex held.
+func (h *AsyncHandler) drop(e *Event) {
+	h.stats.Dropped++
+	if e.Debug {
+		h.stats.DroppedDebug++
+	}
+}
+
+// dropQueued must be called with the mutex held, it drops the event at index i
+// (relative to the head of the queue).
+func (h *AsyncHandler) dropQueued(i int) {
+	h.drop(h.remove(i))
+	h.advance(1)
+}
+
+// remove must be called with the mutex held, it removes the event at index i
+// (relative to the head of the queue) and returns it.
+func (h *AsyncHandler) remove(i int) *Event {
+	n := len(h.queue)
+	e := h.queue[(h.head+i)%n]
+
+	// Shift the events that were in front of the removed one so the queue
+	// remains contiguous.
+	for j := i; j > 0; j-- {
+		h.queue[(h.head+j)%n] = h.queue[(h.head+j-1)%n]
+	}
+
+	h.queue[h.head] = nil
+	h.head = (h.head + 1) % n
+	h.size--
+	return e
+}
+
+// indexDebug must be called with the mutex held, it returns the index of the
+// oldest debug event in the queue, or -1 if there are none.
+func (h *AsyncHandler) indexDebug() int {
+	for i := 0; i != h.size; i++ {
+		if h.queue[(h.head+i)%len(h.queue)].Debug {
+			return i
+		}
+	}
+	return -1
+}
+
+// advance must be called with the mutex held.
+func (h *AsyncHandler) advance(n int) {
+	h.done += uint64(n)
+	close(h.progress)
+	h.progress = make(chan struct{})
+}
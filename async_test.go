@@ -0,0 +1,224 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncHandler(t *testing.T) {
+	t.Run("Flush", func(t *testing.T) {
+		var mutex sync.Mutex
+		var messages []string
+
+		h := NewAsyncHandler(HandlerFunc(func(e *Event) {
+			mutex.Lock()
+			messages = append(messages, e.Message)
+			mutex.Unlock()
+		}), 0, OverflowBlock)
+		defer h.Close(context.Background())
+
+		for _, msg := range []string{"A", "B", "C"} {
+			h.HandleEvent(&Event{Message: msg})
+		}
+
+		if err := h.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if len(messages) != 3 || messages[0] != "A" || messages[1] != "B" || messages[2] != "C" {
+			t.Error("bad messages:", messages)
+		}
+
+		if stats := h.Stats(); stats != (AsyncStats{Handled: 3}) {
+			t.Errorf("bad stats: %+v", stats)
+		}
+	})
+
+	tests := []struct {
+		policy   OverflowPolicy
+		events   []Event
+		messages []string
+		stats    AsyncStats
+	}{
+		{
+			policy:   OverflowDropNewest,
+			events:   []Event{{Message: "B"}, {Message: "C"}, {Message: "D"}},
+			messages: []string{"A", "B", "C"},
+			stats:    AsyncStats{Handled: 3, Dropped: 1},
+		},
+		{
+			policy:   OverflowDropOldest,
+			events:   []Event{{Message: "B"}, {Message: "C"}, {Message: "D"}},
+			messages: []string{"A", "C", "D"},
+			stats:    AsyncStats{Handled: 3, Dropped: 1},
+		},
+		{
+			policy:   OverflowDropDebug,
+			events:   []Event{{Message: "B"}, {Message: "C", Debug: true}, {Message: "D"}, {Message: "E", Debug: true}},
+			messages: []string{"A", "B", "D"},
+			stats:    AsyncStats{Handled: 3, Dropped: 2, DroppedDebug: 2},
+		},
+		{
+			policy:   OverflowDropDebug,
+			events:   []Event{{Message: "B"}, {Message: "C"}, {Message: "D"}},
+			messages: []string{"A", "C", "D"},
+			stats:    AsyncStats{Handled: 3, Dropped: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("overflow", func(t *testing.T) {
+			var messages []string
+			recv := make(chan struct{})
+			wait := make(chan struct{})
+
+			h := NewAsyncHandler(HandlerFunc(func(e *Event) {
+				if e.Message == "A" {
+					close(recv)
+					<-wait
+				}
+				messages = append(messages, e.Message)
+			}), 2, test.policy)
+
+			// Block the background goroutine so the next events fill the
+			// queue.
+			h.HandleEvent(&Event{Message: "A"})
+			<-recv
+
+			for i := range test.events {
+				h.HandleEvent(&test.events[i])
+			}
+
+			close(wait)
+
+			if err := h.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(messages) != len(test.messages) {
+				t.Fatal("bad messages:", messages)
+			}
+			for i := range messages {
+				if messages[i] != test.messages[i] {
+					t.Fatal("bad messages:", messages)
+				}
+			}
+
+			if stats := h.Stats(); stats != test.stats {
+				t.Errorf("bad stats: %+v", stats)
+			}
+		})
+	}
+
+	t.Run("Close", func(t *testing.T) {
+		n := 0
+		h := NewAsyncHandler(HandlerFunc(func(e *Event) { n++ }), 0, OverflowBlock)
+		h.HandleEvent(&Event{})
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		h.HandleEvent(&Event{})
+
+		if n != 1 {
+			t.Error("bad count of handled events:", n)
+		}
+
+		if stats := h.Stats(); stats != (AsyncStats{Handled: 1, Dropped: 1}) {
+			t.Errorf("bad stats: %+v", stats)
+		}
+	})
+
+	t.Run("Close after timeout", func(t *testing.T) {
+		wait := make(chan struct{})
+		c := &closeHandler{}
+		h := NewAsyncHandler(&blockingHandler{closeHandler: c, wait: wait}, 0, OverflowBlock)
+		h.HandleEvent(&Event{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := h.Close(ctx); err != context.DeadlineExceeded {
+			t.Error("bad error:", err)
+		}
+
+		close(wait)
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if c.closed != 1 {
+			t.Error("the wrapped handler must be closed once the queue is drained:", c.closed)
+		}
+
+		h.Close(context.Background())
+
+		if c.closed != 1 {
+			t.Error("the wrapped handler must be closed only once:", c.closed)
+		}
+	})
+
+	t.Run("drop before clone", func(t *testing.T) {
+		wait := make(chan struct{})
+		defer close(wait)
+
+		h := NewAsyncHandler(HandlerFunc(func(e *Event) { <-wait }), 1, OverflowDropNewest)
+		e := &Event{Message: "A", Args: Args{{"name", "Luke"}}}
+		h.HandleEvent(e) // blocks the background goroutine
+
+		for h.Stats().Queued != 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		h.HandleEvent(e) // fills the queue
+
+		if n := testing.AllocsPerRun(100, func() { h.HandleEvent(e) }); n != 0 {
+			t.Error("dropped events must not be cloned:", n)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		wait := make(chan struct{})
+		defer close(wait)
+
+		h := NewAsyncHandler(HandlerFunc(func(e *Event) { <-wait }), 0, OverflowBlock)
+		h.HandleEvent(&Event{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := h.Flush(ctx); err != context.DeadlineExceeded {
+			t.Error("bad error:", err)
+		}
+	})
+}
+
+type blockingHandler struct {
+	*closeHandler
+	wait chan struct{}
+}
+
+func (h *blockingHandler) HandleEvent(e *Event) { <-h.wait }
+
+func BenchmarkAsyncHandler(b *testing.B) {
+	h := NewAsyncHandler(Discard, 0, OverflowDropNewest)
+	defer h.Close(context.Background())
+
+	e := &Event{
+		Message: "Hello Luke!",
+		Source:  "github.com/segmentio/events/async_test.go:42",
+		Args:    Args{{"name", "Luke"}, {"from", "Han"}},
+		Time:    time.Now(),
+	}
+
+	for i := 0; i != b.N; i++ {
+		h.HandleEvent(e)
+	}
+}
@@ -0,0 +1,178 @@
+package events
+
+import "context"
+
+type loggerContextKey struct{}
+
+type argsContextKey struct{}
+
+// ContextWithLogger returns a copy of ctx which carries logger.
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger carried by ctx, or DefaultLogger if
+// there are none.
+func LoggerFromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, _ := ctx.Value(loggerContextKey{}).(*Logger); logger != nil {
+			return logger
+		}
+	}
+	return DefaultLogger
+}
+
+// ContextWithArgs returns a copy of ctx which carries args, appended to the
+// arguments that ctx already carried.
+//
+// The arguments carried by a context are injected in the events produced by
+// the context-aware methods of loggers (LogContext, DebugContext, ...), after
+// the logger's own arguments.
+func ContextWithArgs(ctx context.Context, args Args) context.Context {
+	if len(args) == 0 {
+		return ctx
+	}
+
+	prev := ArgsFromContext(ctx)
+	next := make(Args, 0, len(prev)+len(args))
+	next = append(next, prev...)
+	next = append(next, args...)
+
+	return context.WithValue(ctx, argsContextKey{}, next)
+}
+
+// ArgsAppender is implemented by types producing the arguments carried by
+// contexts lazily (see ContextWithArgsAppender).
+type ArgsAppender interface {
+	// AppendArgs appends the arguments to args and returns the extended list.
+	// The values of the arguments must not be modified afterwards, since
+	// handlers may retain them.
+	AppendArgs(args Args) Args
+}
+
+// ContextWithArgsAppender returns a copy of ctx which carries the arguments
+// appended by a, after the arguments that ctx already carried.
+//
+// Unlike ContextWithArgs, the arguments are not built when the context is
+// created, the AppendArgs method of a is called each time they are injected in
+// an event, which saves the cost of building them for contexts that are never
+// used to log events.
+func ContextWithArgsAppender(ctx context.Context, a ArgsAppender) context.Context {
+	return &ArgsContext{Context: ctx, Appender: a}
+}
+
+// ArgsContext is a context carrying a logger and arguments produced lazily by
+// an ArgsAppender, on top of the values of the context it wraps.
+//
+// Most programs use ContextWithLogger and ContextWithArgsAppender instead.
+// ArgsContext exists for packages that create a context for each unit of work
+// (like a request) and want to carry their own values with a single
+// allocation: they can embed an ArgsContext in the type implementing their
+// context, and delegate to its Value method for the keys they don't handle.
+type ArgsContext struct {
+	context.Context
+
+	// Logger is the logger carried by the context, when nil, the logger of the
+	// wrapped context is used.
+	Logger *Logger
+
+	// Appender produces the arguments carried by the context, appended to the
+	// ones of the wrapped context, when nil, the context only carries the
+	// arguments of the wrapped context.
+	Appender ArgsAppender
+}
+
+// Value satisfies the context.Context interface.
+func (c *ArgsContext) Value(key interface{}) interface{} {
+	switch key {
+	case loggerContextKey{}:
+		if c.Logger != nil {
+			return c.Logger
+		}
+	case argsContextKey{}:
+		if c.Appender != nil {
+			return c
+		}
+	}
+	return c.Context.Value(key)
+}
+
+// ArgsFromContext returns the arguments carried by ctx. The program must not
+// modify the returned list.
+func ArgsFromContext(ctx context.Context) Args {
+	if ctx == nil {
+		return nil
+	}
+	switch v := ctx.Value(argsContextKey{}).(type) {
+	case Args:
+		return v
+	case *ArgsContext:
+		return AppendArgsFromContext(nil, v)
+	default:
+		return nil
+	}
+}
+
+// AppendArgsFromContext appends the arguments carried by ctx to args and
+// returns the extended list.
+func AppendArgsFromContext(args Args, ctx context.Context) Args {
+	if ctx == nil {
+		return args
+	}
+	switch v := ctx.Value(argsContextKey{}).(type) {
+	case Args:
+		return append(args, v...)
+	case *ArgsContext:
+		return v.Appender.AppendArgs(AppendArgsFromContext(args, v.Context))
+	default:
+		return args
+	}
+}
+
+// LogContext emits a log event to the logger carried by ctx, with the
+// arguments carried by ctx.
+func LogContext(ctx context.Context, format string, args ...interface{}) {
+	LoggerFromContext(ctx).log(ctx, 1, false, LevelUnset, format, args...)
+}
+
+// DebugContext emits a debug event to the logger carried by ctx, with the
+// arguments carried by ctx.
+func DebugContext(ctx context.Context, format string, args ...interface{}) {
+	LoggerFromContext(ctx).debug(ctx, 1, format, args...)
+}
+
+// WarnContext emits a warning event to the logger carried by ctx, with the
+// arguments carried by ctx.
+func WarnContext(ctx context.Context, format string, args ...interface{}) {
+	LoggerFromContext(ctx).log(ctx, 1, false, LevelWarn, format, args...)
+}
+
+// ErrorContext emits an error event to the logger carried by ctx, with the
+// arguments carried by ctx.
+func ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	LoggerFromContext(ctx).log(ctx, 1, false, LevelError, format, args...)
+}
+
+// LogContext is like Log but also injects the arguments carried by ctx in the
+// event.
+func (l *Logger) LogContext(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, 1, false, LevelUnset, format, args...)
+}
+
+// DebugContext is like Debug but also injects the arguments carried by ctx in
+// the event.
+func (l *Logger) DebugContext(ctx context.Context, format string, args ...interface{}) {
+	l.debug(ctx, 1, format, args...)
+}
+
+// WarnContext is like Warn but also injects the arguments carried by ctx in the
+// event.
+func (l *Logger) WarnContext(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, 1, false, LevelWarn, format, args...)
+}
+
+// ErrorContext is like Error but also injects the arguments carried by ctx in
+// the event.
+func (l *Logger) ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	l.log(ctx, 1, false, LevelError, format, args...)
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestContextWithLogger(t *testing.T) {
+	ctx := context.Background()
+
+	if logger := LoggerFromContext(ctx); logger != DefaultLogger {
+		t.Error("the default logger must be returned when the context has none")
+	}
+
+	logger := &Logger{}
+	ctx = ContextWithLogger(ctx, logger)
+
+	if l := LoggerFromContext(ctx); l != logger {
+		t.Error("bad logger:", l)
+	}
+}
+
+func TestContextWithArgs(t *testing.T) {
+	ctx1 := ContextWithArgs(context.Background(), Args{{"a", 1}})
+	ctx2 := ContextWithArgs(ctx1, Args{{"b", 2}})
+
+	checkArgs(t, ArgsFromContext(ctx1), Args{{"a", 1}})
+	checkArgs(t, ArgsFromContext(ctx2), Args{{"a", 1}, {"b", 2}})
+
+	if ArgsFromContext(context.Background()) != nil {
+		t.Error("a context without arguments must return nil")
+	}
+}
+
+type countingAppender struct {
+	args  Args
+	calls int
+}
+
+func (a *countingAppender) AppendArgs(args Args) Args {
+	a.calls++
+	return append(args, a.args...)
+}
+
+func TestContextWithArgsAppender(t *testing.T) {
+	a := &countingAppender{args: Args{{"b", 2}}}
+	ctx := ContextWithArgs(context.Background(), Args{{"a", 1}})
+	ctx = ContextWithArgsAppender(ctx, a)
+
+	if a.calls != 0 {
+		t.Error("the arguments must not be built when the context is created")
+	}
+
+	checkArgs(t, ArgsFromContext(ctx), Args{{"a", 1}, {"b", 2}})
+	checkArgs(t, AppendArgsFromContext(Args{{"x", 0}}, ctx), Args{{"x", 0}, {"a", 1}, {"b", 2}})
+	checkArgs(t, ArgsFromContext(ContextWithArgs(ctx, Args{{"c", 3}})), Args{{"a", 1}, {"b", 2}, {"c", 3}})
+
+	var events []*Event
+	logger := NewLogger(HandlerFunc(func(e *Event) { events = append(events, e.Clone()) }))
+	logger.LogContext(ctx, "Hello %{name}s!", "Luke")
+
+	checkEvents(t, events, []*Event{
+		{
+			Message: "Hello Luke!",
+			Args:    Args{{"a", 1}, {"b", 2}, {"name", "Luke"}},
+		},
+	})
+}
+
+func TestArgsContext(t *testing.T) {
+	logger := &Logger{}
+	a := &countingAppender{args: Args{{"b", 2}}}
+	parent := ContextWithArgs(context.Background(), Args{{"a", 1}})
+
+	ctx := &ArgsContext{Context: parent}
+
+	if l := LoggerFromContext(ctx); l != DefaultLogger {
+		t.Error("a context without a logger must use the logger of its parent:", l)
+	}
+	checkArgs(t, ArgsFromContext(ctx), Args{{"a", 1}})
+
+	ctx.Logger = logger
+	ctx.Appender = a
+
+	if l := LoggerFromContext(ctx); l != logger {
+		t.Error("bad logger:", l)
+	}
+	checkArgs(t, ArgsFromContext(ctx), Args{{"a", 1}, {"b", 2}})
+}
+
+func TestLoggerContext(t *testing.T) {
+	var events []*Event
+
+	logger := &Logger{
+		Handler: HandlerFunc(func(e *Event) {
+			events = append(events, e.Clone())
+		}),
+		Args:         Args{{"service", "api"}},
+		EnableSource: true,
+		EnableDebug:  true,
+	}
+
+	ctx := ContextWithArgs(context.Background(), Args{{"method", "GET"}})
+	ctx = ContextWithLogger(ctx, logger)
+
+	logger.LogContext(ctx, "Hello %{name}s!", "Luke", Args{{"from", "Han"}})
+	logger.DebugContext(ctx, "debug")
+	logger.WarnContext(ctx, "warn")
+	logger.ErrorContext(ctx, "error")
+	LogContext(ctx, "top-level")
+
+	for _, e := range events {
+		if !strings.Contains(e.Source, "context_test.go:") {
+			t.Error("bad source:", e.Source)
+		}
+	}
+
+	checkEvents(t, events, []*Event{
+		{
+			Message: "Hello Luke!",
+			Args:    Args{{"service", "api"}, {"method", "GET"}, {"name", "Luke"}, {"from", "Han"}},
+		},
+		{
+			Message: "debug",
+			Args:    Args{{"service", "api"}, {"method", "GET"}},
+			Debug:   true,
+		},
+		{
+			Message: "warn",
+			Args:    Args{{"service", "api"}, {"method", "GET"}},
+			Level:   LevelWarn,
+		},
+		{
+			Message: "error",
+			Args:    Args{{"service", "api"}, {"method", "GET"}},
+			Level:   LevelError,
+		},
+		{
+			Message: "top-level",
+			Args:    Args{{"service", "api"}, {"method", "GET"}},
+		},
+	})
+}
+
+func checkArgs(t *testing.T, args Args, expected Args) {
+	t.Helper()
+	if len(args) != len(expected) {
+		t.Errorf("bad args: %v != %v", args, expected)
+		return
+	}
+	for i := range args {
+		if args[i] != expected[i] {
+			t.Errorf("bad args: %v != %v", args, expected)
+			return
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DebugFilterEnv is the name of the environment variable that the debug
+// selection spec of DefaultDebugFilter is loaded from.
+//
+// If the value of the variable starts with '@', the rest of the value is the
+// path to a file containing the spec.
+const DebugFilterEnv = "EVENTS_DEBUG"
+
+// DefaultDebugFilter is the debug filter of the default logger and of the
+// loggers created by NewLogger, it is initialized from the EVENTS_DEBUG
+// environment variable when the program starts.
+var DefaultDebugFilter = &DebugFilter{}
+
+// DebugFilter selects which call sites are allowed to produce debug events,
+// based on the package that they belong to.
+//
+// The filter is configured by a spec made of comma-separated rules of the form
+// pattern=on or pattern=off (the value may be omitted and defaults to on), for
+// example:
+//
+//	github.com/acme/db/*=on,github.com/acme/http=off
+//
+// Patterns are matched against the import path of the package of the call
+// sites (derived from the function name of runtime.Frame), where '*' matches
+// any sequence of characters, including '/'. A pattern ending with "/*" also
+// matches the package that it is a prefix of. When multiple rules match a call
+// site, the last one wins.
+//
+// The result of evaluating the rules is cached for each call site, so checking
+// whether debug events are enabled remains cheap.
+//
+// The zero-value is a valid filter with no rules. Filters are safe to use
+// concurrently from multiple goroutines, and satisfy the flag.Value interface.
+type DebugFilter struct {
+	config atomic.Pointer[debugFilterConfig]
+}
+
+type debugFilterConfig struct {
+	spec  string
+	rules []debugRule
+	cache sync.Map // map[uintptr]debugMatch
+}
+
+type debugRule struct {
+	pattern string
+	enable  bool
+}
+
+type debugMatch int8
+
+const (
+	debugNoMatch debugMatch = iota
+	debugOn
+	debugOff
+)
+
+// NewDebugFilter creates a new debug filter configured with spec.
+func NewDebugFilter(spec string) (*DebugFilter, error) {
+	f := &DebugFilter{}
+	if err := f.Set(spec); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Set replaces the rules of the filter with those of spec. The filter is left
+// unchanged if spec is invalid.
+func (f *DebugFilter) Set(spec string) error {
+	rules, err := parseDebugSpec(spec)
+	if err != nil {
+		return err
+	}
+	f.config.Store(&debugFilterConfig{
+		spec:  spec,
+		rules: rules,
+	})
+	return nil
+}
+
+// String returns the spec that the filter was configured with.
+func (f *DebugFilter) String() string {
+	if c := f.config.Load(); c != nil {
+		return c.spec
+	}
+	return ""
+}
+
+// Lookup evaluates the rules of the filter for the call site at the program
+// counter address pc. The found return value is false if no rules matched the
+// call site, in which case enable is false as well.
+func (f *DebugFilter) Lookup(pc uintptr) (enable bool, found bool) {
+	switch f.match(pc) {
+	case debugOn:
+		return true, true
+	case debugOff:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// empty returns true if the filter has no rules, which lets the logger skip
+// capturing the program counter of its caller.
+func (f *DebugFilter) empty() bool {
+	c := f.config.Load()
+	return c == nil || len(c.rules) == 0
+}
+
+func (f *DebugFilter) match(pc uintptr) debugMatch {
+	c := f.config.Load()
+	if c == nil || len(c.rules) == 0 || pc == 0 {
+		return debugNoMatch
+	}
+
+	if m, ok := c.cache.Load(pc); ok {
+		return m.(debugMatch)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := funcPackage(frame.Function)
+	m := debugNoMatch
+
+	for _, r := range c.rules {
+		if matchPattern(r.pattern, pkg) || matchPattern(r.pattern, pkg+"/") {
+			if r.enable {
+				m = debugOn
+			} else {
+				m = debugOff
+			}
+		}
+	}
+
+	c.cache.Store(pc, m)
+	return m
+}
+
+// funcPackage returns the import path of the package of the function named fn,
+// in the format of runtime.Frame.Function (for example
+// "github.com/acme/db.(*Conn).Query"). The linker escapes the dots of the last
+// element of import paths, those are restored.
+func funcPackage(fn string) string {
+	i := strings.LastIndexByte(fn, '/') + 1
+	if j := strings.IndexByte(fn[i:], '.'); j >= 0 {
+		fn = fn[:i+j]
+	}
+	return strings.ReplaceAll(fn, "%2e", ".")
+}
+
+// LoadDebugFilter reloads the spec of DefaultDebugFilter from the EVENTS_DEBUG
+// environment variable. The rules of the filter are cleared if the variable is
+// not set.
+func LoadDebugFilter() error {
+	spec := os.Getenv(DebugFilterEnv)
+
+	if strings.HasPrefix(spec, "@") {
+		b, err := os.ReadFile(spec[1:])
+		if err != nil {
+			return fmt.Errorf("events: loading debug filter: %w", err)
+		}
+		spec = strings.TrimSpace(string(b))
+	}
+
+	return DefaultDebugFilter.Set(spec)
+}
+
+func parseDebugSpec(spec string) ([]debugRule, error) {
+	var rules []debugRule
+
+	for _, s := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		pattern, value, hasValue := strings.Cut(s, "=")
+		pattern = strings.TrimSpace(pattern)
+		enable := true
+
+		if hasValue {
+			switch strings.ToLower(strings.TrimSpace(value)) {
+			case "on", "true", "1":
+			case "off", "false", "0":
+				enable = false
+			default:
+				return nil, fmt.Errorf("events: invalid debug filter rule %q: the value must be on or off", s)
+			}
+		}
+
+		if len(pattern) == 0 {
+			return nil, fmt.Errorf("events: invalid debug filter rule %q: missing pattern", s)
+		}
+
+		rules = append(rules, debugRule{pattern: pattern, enable: enable})
+	}
+
+	return rules, nil
+}
+
+// matchPattern returns true if s matches pattern, where '*' matches any
+// sequence of characters.
+func matchPattern(pattern, s string) bool {
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, pattern[:i]) {
+		return false
+	}
+
+	// Try every possible length for the sequence matched by '*', patterns are
+	// short so the backtracking remains cheap.
+	pattern, s = pattern[i+1:], s[i:]
+	for j := 0; j <= len(s); j++ {
+		if matchPattern(pattern, s[j:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	// Errors are ignored here because handlers are not configured yet, the
+	// program can call LoadDebugFilter to get them.
+	LoadDebugFilter()
+}
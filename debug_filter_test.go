@@ -0,0 +1,199 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"github.com/acme/db", "github.com/acme/db", true},
+		{"github.com/acme/db", "github.com/acme/db/sql", false},
+		{"github.com/acme/db/*", "github.com/acme/db/", true},
+		{"github.com/acme/db/*", "github.com/acme/db/sql", true},
+		{"github.com/acme/db/*", "github.com/acme/db/sql/driver", true},
+		{"github.com/acme/db/*", "github.com/acme/dbx", false},
+		{"*/http", "github.com/acme/http", true},
+		{"*/http", "github.com/acme/http2", false},
+		{"github.com/*/http", "github.com/acme/http", true},
+		{"*", "github.com/acme/http", true},
+	}
+
+	for _, test := range tests {
+		if match := matchPattern(test.pattern, test.s); match != test.match {
+			t.Errorf("matchPattern(%q, %q): %t != %t", test.pattern, test.s, match, test.match)
+		}
+	}
+}
+
+func TestDebugFilterSet(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"github.com/acme/db/*=on,github.com/acme/http=off",
+		"github.com/acme/db, github.com/acme/http = false",
+		"*=off\ngithub.com/acme/db=on\n",
+	} {
+		f, err := NewDebugFilter(spec)
+		if err != nil {
+			t.Errorf("%q: %s", spec, err)
+			continue
+		}
+		if s := f.String(); s != spec {
+			t.Errorf("%q: bad spec: %q", spec, s)
+		}
+	}
+
+	for _, spec := range []string{
+		"github.com/acme/db=maybe",
+		"=on",
+	} {
+		if _, err := NewDebugFilter(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+// pkg is the import path that debug filters match the call sites of the tests
+// against.
+const pkg = "github.com/segmentio/events/v2"
+
+func TestDebugFilterLookup(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	pc := pcs[0]
+
+	tests := []struct {
+		spec   string
+		enable bool
+		found  bool
+	}{
+		{"", false, false},
+		{"github.com/acme/*", false, false},
+		{pkg, true, true},
+		{pkg + "=off", false, true},
+		{"*=on," + pkg + "=off", false, true},
+		{pkg + "=off,*", true, true},
+		{"github.com/segmentio/events/*", true, true},
+		{"github.com/segmentio/events/v2/*", true, true},
+		{"github.com/segmentio/*=off,github.com/segmentio/events/v2=on", true, true},
+	}
+
+	for _, test := range tests {
+		f, _ := NewDebugFilter(test.spec)
+
+		for i := 0; i != 2; i++ { // the second lookup hits the cache
+			enable, found := f.Lookup(pc)
+			if enable != test.enable || found != test.found {
+				t.Errorf("%q: bad lookup: (%t, %t) != (%t, %t)", test.spec, enable, found, test.enable, test.found)
+			}
+		}
+	}
+}
+
+func TestLoggerDebugFilter(t *testing.T) {
+	tests := []struct {
+		spec        string
+		enableDebug bool
+		count       int
+	}{
+		{"", true, 1},
+		{"", false, 0},
+		{pkg + "=off", true, 0},
+		{pkg + "=on", false, 1},
+		{"github.com/acme/*=on", false, 0},
+		{"github.com/acme/*=off", true, 1},
+	}
+
+	for _, test := range tests {
+		count := 0
+		filter, _ := NewDebugFilter(test.spec)
+		logger := &Logger{
+			Handler:     HandlerFunc(func(e *Event) { count++ }),
+			EnableDebug: test.enableDebug,
+			DebugFilter: filter,
+		}
+
+		logger.Debug("Hello World!")
+
+		if count != test.count {
+			t.Errorf("%q (EnableDebug=%t): bad number of events: %d != %d", test.spec, test.enableDebug, count, test.count)
+		}
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		fn  string
+		pkg string
+	}{
+		{"main.main", "main"},
+		{"github.com/acme/db.Open", "github.com/acme/db"},
+		{"github.com/acme/db.(*Conn).Query", "github.com/acme/db"},
+		{"github.com/acme/db.Open.func1", "github.com/acme/db"},
+		{"github.com/acme/db.Map[...].Get", "github.com/acme/db"},
+		{"gopkg.in/yaml%2ev3.Marshal", "gopkg.in/yaml.v3"},
+	}
+
+	for _, test := range tests {
+		if pkg := funcPackage(test.fn); pkg != test.pkg {
+			t.Errorf("%s: %q != %q", test.fn, pkg, test.pkg)
+		}
+	}
+}
+
+func TestLoadDebugFilter(t *testing.T) {
+	defer DefaultDebugFilter.Set(DefaultDebugFilter.String())
+
+	t.Setenv(DebugFilterEnv, "github.com/acme/db=on")
+
+	if err := LoadDebugFilter(); err != nil {
+		t.Fatal(err)
+	}
+	if s := DefaultDebugFilter.String(); s != "github.com/acme/db=on" {
+		t.Error("bad spec:", s)
+	}
+
+	file := filepath.Join(t.TempDir(), "debug")
+	os.WriteFile(file, []byte("github.com/acme/http=off\n"), 0644)
+	t.Setenv(DebugFilterEnv, "@"+file)
+
+	if err := LoadDebugFilter(); err != nil {
+		t.Fatal(err)
+	}
+	if s := DefaultDebugFilter.String(); s != "github.com/acme/http=off" {
+		t.Error("bad spec:", s)
+	}
+
+	t.Setenv(DebugFilterEnv, "@"+file+".missing")
+
+	if err := LoadDebugFilter(); err == nil {
+		t.Error("expected an error loading a missing file")
+	}
+}
+
+func BenchmarkLoggerDebugFilter(b *testing.B) {
+	filter, _ := NewDebugFilter("github.com/acme/*=on")
+	logger := &Logger{
+		Handler:     Discard,
+		DebugFilter: filter,
+	}
+
+	for i := 0; i != b.N; i++ {
+		logger.Debug("Hello World!")
+	}
+}
+
+func TestNewLoggerDebugFilter(t *testing.T) {
+	if f := NewLogger(nil).DebugFilter; f != DefaultDebugFilter {
+		t.Error("loggers created by NewLogger must use the default debug filter:", f)
+	}
+	if f := DefaultLogger.DebugFilter; f != DefaultDebugFilter {
+		t.Error("the default logger must use the default debug filter:", f)
+	}
+}
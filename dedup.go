@@ -0,0 +1,299 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DedupHandler is an event handler which collapses identical events received
+// within a time window.
+//
+// The first occurrence of an event is passed to the handler immediately, the
+// following ones are counted until the window expires. At the end of each
+// window, a single event is produced for each event that was repeated, with
+// these extra arguments:
+// - "repeat_count" is the number of repetitions that were collapsed.
+// - "first_seen" is the time at which the first occurrence was seen.
+// - "last_seen" is the time at which the last repetition was seen.
+//
+// Events are identical when they have the same message and source, and when
+// CompareArgs is true, the same argument values. The collapsed event is a copy
+// of the first repetition.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type DedupHandler struct {
+	// CompareArgs controls whether argument values are compared to decide if
+	// two events are identical. It must be set before the handler is used.
+	CompareArgs bool
+
+	handler Handler
+	mutex   sync.Mutex
+	pending map[string]*dedupEntry
+	closed  bool // events received after Close are passed through
+	once    sync.Once
+	done    chan struct{}
+	exit    chan struct{}
+}
+
+type dedupEntry struct {
+	event *Event // clone of the first repetition, nil if there were none
+	count int    // number of repetitions
+	first time.Time
+	last  time.Time
+}
+
+// DefaultDedupWindow is the duration of the windows of a DedupHandler when none
+// is specified.
+const DefaultDedupWindow = 1 * time.Second
+
+// NewDedupHandler creates a new deduplicating handler which passes events to
+// handler, collapsing repetitions within windows of the given duration. If
+// window is zero or negative, DefaultDedupWindow is used.
+//
+// The handler starts a background goroutine which emits the collapsed events
+// at the end of each window, the program must call Close to stop it.
+func NewDedupHandler(handler Handler, window time.Duration) *DedupHandler {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+
+	h := &DedupHandler{
+		handler: handler,
+		pending: make(map[string]*dedupEntry),
+		done:    make(chan struct{}),
+		exit:    make(chan struct{}),
+	}
+	go h.run(time.NewTicker(window))
+	return h
+}
+
+// HandleEvent satisfies the Handler interface.
+func (h *DedupHandler) HandleEvent(e *Event) {
+	now := e.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// The key is computed before acquiring the mutex, it may resolve lazy
+	// values of the event arguments.
+	k := dedupKeyPool.Get().(*dedupKey)
+	defer dedupKeyPool.Put(k)
+	k.b = h.appendKey(k.b[:0], e)
+
+	h.mutex.Lock()
+
+	if h.closed {
+		h.mutex.Unlock()
+		h.handler.HandleEvent(e)
+		return
+	}
+
+	if entry := h.pending[string(k.b)]; entry != nil {
+		entry.count++
+		entry.last = now
+
+		// Only the first repetition is cloned, the following ones only update
+		// the counters of the entry.
+		if entry.event == nil {
+			entry.event = e.Clone()
+		}
+
+		h.mutex.Unlock()
+		return
+	}
+
+	h.pending[string(k.b)] = &dedupEntry{first: now, last: now}
+	h.mutex.Unlock()
+
+	h.handler.HandleEvent(e)
+}
+
+// Flush emits the collapsed events that are pending in the current window, then
+// flushes the handler.
+func (h *DedupHandler) Flush(ctx context.Context) error {
+	h.flush()
+	return FlushHandler(ctx, h.handler)
+}
+
+// Close stops the background goroutine of the handler, emits the collapsed
+// events that are pending, then closes the handler. Events received after Close
+// was called are passed to the handler without being deduplicated.
+func (h *DedupHandler) Close(ctx context.Context) error {
+	h.once.Do(func() { close(h.done) })
+
+	select {
+	case <-h.exit:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	h.mutex.Lock()
+	h.closed = true
+	h.mutex.Unlock()
+
+	h.flush()
+	return CloseHandler(ctx, h.handler)
+}
+
+func (h *DedupHandler) run(ticker *time.Ticker) {
+	defer close(h.exit)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.flush()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+func (h *DedupHandler) flush() {
+	var repeated []*dedupEntry
+
+	h.mutex.Lock()
+	for key, entry := range h.pending {
+		if entry.count != 0 {
+			repeated = append(repeated, entry)
+		}
+		delete(h.pending, key)
+	}
+	h.mutex.Unlock()
+
+	sort.Slice(repeated, func(i int, j int) bool {
+		return repeated[i].first.Before(repeated[j].first)
+	})
+
+	for _, entry := range repeated {
+		e := entry.event
+		e.Args = append(e.Args,
+			Arg{"repeat_count", entry.count},
+			Arg{"first_seen", entry.first},
+			Arg{"last_seen", entry.last},
+		)
+		h.handler.HandleEvent(e)
+	}
+}
+
+func (h *DedupHandler) appendKey(b []byte, e *Event) []byte {
+	b = append(b, e.Source...)
+	b = append(b, 0)
+	b = append(b, e.Message...)
+
+	if h.CompareArgs {
+		for _, a := range e.Args {
+			b = append(b, 0)
+			b = append(b, a.Name...)
+			b = append(b, '=')
+			b = appendDedupValue(b, Resolve(a.Value))
+		}
+	}
+
+	return b
+}
+
+// appendDedupValue writes a representation of v to b which depends only on the
+// value, pointers are followed instead of having their addresses written, so
+// equal values held by different pointers produce the same key.
+func appendDedupValue(b []byte, v interface{}) []byte {
+	x := Any(v)
+	b = append(b, byte(x.Kind()))
+
+	if x.Kind() != KindAny {
+		return x.append(b)
+	}
+
+	if x.any == nil {
+		return append(b, "nil"...)
+	}
+
+	r := reflect.ValueOf(x.any)
+	b = append(b, r.Type().String()...)
+	b = append(b, ':')
+	return appendDedupReflect(b, r, 0)
+}
+
+func appendDedupReflect(b []byte, v reflect.Value, depth int) []byte {
+	if depth == maxDedupDepth {
+		return append(b, "..."...)
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return append(b, "nil"...)
+
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(b, "nil"...)
+		}
+		return appendDedupReflect(b, v.Elem(), depth+1)
+
+	case reflect.Struct:
+		b = append(b, '{')
+		for i := 0; i < v.NumField(); i++ {
+			if i != 0 {
+				b = append(b, ' ')
+			}
+			b = append(b, v.Type().Field(i).Name...)
+			b = append(b, ':')
+			b = appendDedupReflect(b, v.Field(i), depth+1)
+		}
+		return append(b, '}')
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return append(b, "nil"...)
+		}
+		b = append(b, '[')
+		for i := 0; i < v.Len(); i++ {
+			if i != 0 {
+				b = append(b, ' ')
+			}
+			b = appendDedupReflect(b, v.Index(i), depth+1)
+		}
+		return append(b, ']')
+
+	case reflect.Map:
+		if v.IsNil() {
+			return append(b, "nil"...)
+		}
+		// Map entries are sorted by the representation of their keys so the
+		// iteration order doesn't change the result.
+		entries := make([]string, 0, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			e := appendDedupReflect(nil, it.Key(), depth+1)
+			e = append(e, ':')
+			e = appendDedupReflect(e, it.Value(), depth+1)
+			entries = append(entries, string(e))
+		}
+		sort.Strings(entries)
+		b = append(b, "map["...)
+		for i, e := range entries {
+			if i != 0 {
+				b = append(b, ' ')
+			}
+			b = append(b, e...)
+		}
+		return append(b, ']')
+
+	default:
+		return fmt.Append(b, v)
+	}
+}
+
+// maxDedupDepth limits the depth of the values walked by appendDedupReflect,
+// protecting the handler from cyclic values.
+const maxDedupDepth = 32
+
+type dedupKey struct {
+	b []byte
+}
+
+var dedupKeyPool = sync.Pool{
+	New: func() interface{} { return &dedupKey{b: make([]byte, 0, 256)} },
+}
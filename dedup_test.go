@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupHandler(t *testing.T) {
+	tests := []struct {
+		compareArgs bool
+		messages    []string
+		repeats     []interface{}
+	}{
+		{
+			compareArgs: false,
+			messages:    []string{"A", "B", "A", "B"},
+			repeats:     []interface{}{nil, nil, 3, 1},
+		},
+		{
+			compareArgs: true,
+			messages:    []string{"A", "A", "B", "A", "B"},
+			repeats:     []interface{}{nil, nil, nil, 2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			var evlist []*Event
+			now := time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC)
+
+			h := NewDedupHandler(HandlerFunc(func(e *Event) {
+				evlist = append(evlist, e.Clone())
+			}), time.Hour)
+			h.CompareArgs = test.compareArgs
+
+			h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now, Args: Args{{"n", 1}}})
+			h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now.Add(1 * time.Second), Args: Args{{"n", 2}}})
+			h.HandleEvent(&Event{Message: "B", Source: "b.go:1", Time: now.Add(2 * time.Second)})
+			h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now.Add(3 * time.Second), Args: Args{{"n", 1}}})
+			h.HandleEvent(&Event{Message: "B", Source: "b.go:1", Time: now.Add(4 * time.Second)})
+			h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now.Add(5 * time.Second), Args: Args{{"n", 1}}})
+
+			if err := h.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			messages := []string{}
+			repeats := []interface{}{}
+
+			for _, e := range evlist {
+				v, _ := e.Args.Get("repeat_count")
+				messages = append(messages, e.Message)
+				repeats = append(repeats, v)
+			}
+
+			if !reflect.DeepEqual(messages, test.messages) {
+				t.Error("bad messages:", messages)
+			}
+
+			if !reflect.DeepEqual(repeats, test.repeats) {
+				t.Error("bad repeat counts:", repeats)
+			}
+
+			last := evlist[len(evlist)-1]
+
+			if v, _ := last.Args.Get("first_seen"); v != now.Add(2*time.Second) {
+				t.Error("bad first_seen:", v)
+			}
+
+			if v, _ := last.Args.Get("last_seen"); v != now.Add(4*time.Second) {
+				t.Error("bad last_seen:", v)
+			}
+		})
+	}
+}
+
+func TestDedupHandlerWindow(t *testing.T) {
+	evchan := make(chan *Event, 10)
+
+	h := NewDedupHandler(HandlerFunc(func(e *Event) {
+		evchan <- e.Clone()
+	}), 10*time.Millisecond)
+	defer h.Close(context.Background())
+
+	for i := 0; i != 3; i++ {
+		h.HandleEvent(&Event{Message: "connection refused"})
+	}
+
+	for _, count := range []interface{}{nil, 2} {
+		select {
+		case e := <-evchan:
+			if v, _ := e.Args.Get("repeat_count"); v != count {
+				t.Error("bad repeat count:", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no events received within 1 second")
+		}
+	}
+}
+
+func TestDedupHandlerCloneOnce(t *testing.T) {
+	h := NewDedupHandler(Discard, time.Hour)
+	defer h.Close(context.Background())
+
+	clones := 0
+	e := &Event{Message: "connection refused", Args: Args{{"n", Lazy(func() interface{} {
+		clones++
+		return 1
+	})}}}
+
+	for i := 0; i != 10000; i++ {
+		h.HandleEvent(e)
+	}
+
+	if clones != 1 {
+		t.Error("repetitions must be cloned once:", clones)
+	}
+}
+
+func TestDedupHandlerZeroWindow(t *testing.T) {
+	h := NewDedupHandler(Discard, 0)
+	h.HandleEvent(&Event{Message: "A"})
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDedupHandlerCompareArgsPointers(t *testing.T) {
+	type point struct {
+		X, Y *int
+	}
+
+	newPoint := func(x, y int) *point { return &point{X: &x, Y: &y} }
+	count := 0
+
+	h := NewDedupHandler(HandlerFunc(func(e *Event) { count++ }), time.Hour)
+	defer h.Close(context.Background())
+	h.CompareArgs = true
+
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"p", newPoint(1, 2)}, {"m", map[string]int{"a": 1, "b": 2}}}})
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"p", newPoint(1, 2)}, {"m", map[string]int{"b": 2, "a": 1}}}})
+
+	if count != 1 {
+		t.Error("equal values held by different pointers must be deduplicated:", count)
+	}
+
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"p", newPoint(1, 3)}, {"m", map[string]int{"a": 1, "b": 2}}}})
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"p", 1}}})
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"p", "1"}}})
+
+	if count != 4 {
+		t.Error("different values must not be deduplicated:", count)
+	}
+}
+
+func TestDedupHandlerAfterClose(t *testing.T) {
+	count := 0
+
+	h := NewDedupHandler(HandlerFunc(func(e *Event) { count++ }), time.Hour)
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i != 3; i++ {
+		h.HandleEvent(&Event{Message: "A"})
+	}
+
+	if count != 3 {
+		t.Error("events received after Close must be passed to the handler:", count)
+	}
+}
@@ -2,14 +2,18 @@ package ecslogs
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/segmentio/encoding/json"
@@ -41,27 +45,44 @@ func (h *Handler) HandleEvent(e *events.Event) {
 	f := fmtPool.Get().(*formatter)
 	f.buffer.Reset()
 
-	f.level = "INFO"
+	f.level = e.Severity().String()
 	// It's not super realistic to expect more precise timestamps on a Unix
 	// server, and the additional fidelity doesn't help much either vs.
 	// cluttering up the log line
 	f.time = e.Time.Round(time.Microsecond)
 	f.message = e.Message
-	f.data.args = e.Args
 	f.info.Source = e.Source
 	f.info.Program = h.Program
 	f.info.Pid = h.Pid
 
-	if e.Debug {
-		f.level = "DEBUG"
-	}
-
+	// Valuers are resolved once into the formatter state, they may be
+	// expensive to call and are not guaranteed to return the same values.
 	for _, a := range e.Args {
-		if err, ok := a.Value.(error); ok {
-			f.level = "ERROR"
-			f.info.Errors = append(f.info.Errors, makeEventError(err))
+		_, lazy := a.Value.(events.Valuer)
+		a.Value = events.Resolve(a.Value)
+
+		// Errors held by values are reported like errors passed directly.
+		if err, ok := valueError(a.Value); ok {
+			a.Value = err
+		}
+
+		// Valuers are not inspected when determining the severity of events,
+		// the errors they produce are reported as data to stay consistent with
+		// the level of the event.
+		if err, ok := a.Value.(error); ok && lazy {
+			a.Value = err.Error()
+		}
+
+		f.args = append(f.args, a)
+
+		switch v := a.Value.(type) {
+		case error:
+			f.appendError(v, e.Stack)
+		case events.Group:
+			f.appendGroupErrors(events.Args(v), e.Stack)
 		}
 	}
+	f.data.args = f.args
 
 	f.encoder.Encode(f.value)
 
@@ -69,11 +90,78 @@ func (h *Handler) HandleEvent(e *events.Event) {
 	h.Output.Write(f.buffer.b)
 	h.mutex.Unlock()
 
+	for i := range f.args {
+		f.args[i] = events.Arg{}
+	}
+
+	f.args = f.args[:0]
+	f.data.args = nil
 	f.info.Source = ""
 	f.info.Errors = f.info.Errors[:0]
+	f.stack = f.stack[:0]
 	fmtPool.Put(f)
 }
 
+// appendError reports err in the info section of the event. Errors which don't
+// carry a stack are reported with the stack captured by the logger, if any.
+func (f *formatter) appendError(err error, stack []uintptr) {
+	ee := makeEventError(err)
+
+	if len(ee.Stack) == 0 && len(stack) != 0 {
+		ee.Stack = f.eventStack(stack)
+	}
+
+	f.info.Errors = append(f.info.Errors, ee)
+}
+
+// appendGroupErrors reports the errors held by the arguments of a group, and of
+// the groups nested in it. Unlike top-level errors, they remain in the data
+// section as well so the group they belong to is preserved.
+func (f *formatter) appendGroupErrors(args events.Args, stack []uintptr) {
+	for _, a := range args {
+		switch v := a.Value.(type) {
+		case error:
+			f.appendError(v, stack)
+		case events.Group:
+			f.appendGroupErrors(events.Args(v), stack)
+		default:
+			if err, ok := valueError(v); ok {
+				f.appendError(err, stack)
+			}
+		}
+	}
+}
+
+// valueError returns the error held by v if it is an events.Value, or a
+// pointer to one, of kind events.KindAny holding an error.
+func valueError(v interface{}) (error, bool) {
+	switch x := v.(type) {
+	case events.Value:
+		if x.Kind() == events.KindAny {
+			err, ok := x.Any().(error)
+			return err, ok
+		}
+	case *events.Value:
+		if x != nil && x.Kind() == events.KindAny {
+			err, ok := x.Any().(error)
+			return err, ok
+		}
+	}
+	return nil, false
+}
+
+// Flush satisfies the events.Flusher interface, it flushes the handler's output
+// if it has a Flush method (like *bufio.Writer).
+func (h *Handler) Flush(ctx context.Context) error {
+	f, ok := h.Output.(interface{ Flush() error })
+	if !ok {
+		return nil
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return f.Flush()
+}
+
 type event struct {
 	Level   *string    `json:"level"`
 	Time    *time.Time `json:"time"`
@@ -90,33 +178,105 @@ type eventInfo struct {
 }
 
 type eventError struct {
-	Type  string     `json:"type,omitempty"`
-	Error string     `json:"error,omitempty"`
-	Errno int        `json:"errno,omitempty"`
-	Stack stackTrace `json:"stack,omitempty"`
+	Type   string       `json:"type,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Errno  int          `json:"errno,omitempty"`
+	Stack  stackTrace   `json:"stack,omitempty"`
+	Causes []eventError `json:"causes,omitempty"`
 }
 
+// maxErrorDepth limits the depth of the error trees reported by the handler,
+// protecting it from errors which wrap themselves.
+const maxErrorDepth = 32
+
+// makeEventError converts err to its representation in the errors section of
+// events. The type reported for err is the type of its root cause, and the
+// stack is the deepest stack trace found in the error tree, which is usually
+// the one closest to where the error originated. The errors wrapped by err are
+// reported in the tree of causes.
 func makeEventError(err error) eventError {
-	cause := errors.Cause(err)
-	etype := reflect.TypeOf(cause).String()
-	error := err.Error()
-	errno := 0
-	var stack stackTrace
-
-	if se, ok := cause.(syscall.Errno); ok {
-		errno = int(se)
+	e := makeErrorNode(err, 0)
+	e.Type = reflect.TypeOf(rootCause(err)).String()
+	e.Stack = findStack(err, 0)
+	return e
+}
+
+func makeErrorNode(err error, depth int) eventError {
+	e := eventError{
+		Type:  reflect.TypeOf(err).String(),
+		Error: err.Error(),
 	}
 
-	if st, ok := err.(stackTracer); ok {
-		stack = stackTrace(st.StackTrace())
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		e.Errno = int(errno)
+	}
+
+	if depth < maxErrorDepth {
+		for _, cause := range unwrapError(err) {
+			e.Causes = append(e.Causes, makeErrorNode(cause, depth+1))
+		}
+	}
+
+	return e
+}
+
+// unwrapError returns the errors directly wrapped by err, supporting the
+// Unwrap() error and Unwrap() []error methods of the standard library, as well
+// as the Cause() error method of github.com/pkg/errors.
+func unwrapError(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		errs := make([]error, 0, len(x.Unwrap()))
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return errs
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			return []error{e}
+		}
+	case causer:
+		if e := x.Cause(); e != nil {
+			return []error{e}
+		}
+	}
+	return nil
+}
+
+// rootCause follows the chain of errors wrapping a single error, it stops at
+// errors wrapping multiple errors.
+func rootCause(err error) error {
+	for depth := 0; depth < maxErrorDepth; depth++ {
+		causes := unwrapError(err)
+		if len(causes) != 1 {
+			break
+		}
+		err = causes[0]
 	}
+	return err
+}
 
-	return eventError{
-		Type:  etype,
-		Error: error,
-		Errno: errno,
-		Stack: stack,
+// findStack returns the deepest stack trace found in the tree of err, the
+// first one in the order of the tree when multiple errors are wrapped.
+func findStack(err error, depth int) stackTrace {
+	if depth < maxErrorDepth {
+		for _, cause := range unwrapError(err) {
+			if st := findStack(cause, depth+1); len(st) != 0 {
+				return st
+			}
+		}
 	}
+	if st, ok := err.(stackTracer); ok {
+		return stackTrace(st.StackTrace())
+	}
+	return nil
+}
+
+type causer interface {
+	Cause() error
 }
 
 type eventData struct {
@@ -130,35 +290,155 @@ func (data *eventData) MarshalJSON() ([]byte, error) {
 
 	b := &bytes.Buffer{}
 	b.Grow(64)
-	b.WriteByte('{')
+	encodeArgs(b, json.NewEncoder(b), data.args, true)
+	return b.Bytes(), nil
+}
 
+// encodeArgs writes args to b as a JSON object, events.Group values are encoded
+// as nested objects. Top-level errors are skipped since they are reported in
+// the info section, the values of nested arguments are resolved.
+func encodeArgs(b *bytes.Buffer, e *json.Encoder, args events.Args, top bool) {
+	b.WriteByte('{')
 	n := 0
-	i := data.next(0)
-	e := json.NewEncoder(b)
 
-	for i < len(data.args) {
+	for i := range args {
+		v := args[i].Value
+
+		if top {
+			if _, ok := v.(error); ok {
+				continue
+			}
+		} else {
+			v = events.Resolve(v)
+		}
+
 		if n != 0 {
 			b.WriteByte(',')
 		}
-		fmt.Fprintf(b, `%q:`, jsonString{&data.args[i].Name})
-		e.Encode(&data.args[i].Value)
-		b.Truncate(b.Len() - 1) // remove trailing '\n'
-		i = data.next(i + 1)
+		fmt.Fprintf(b, `%q:`, jsonString{&args[i].Name})
+
+		switch x := v.(type) {
+		case events.Value:
+			encodeValue(b, e, &x)
+		case *events.Value:
+			encodeValue(b, e, x)
+		case events.Group:
+			encodeArgs(b, e, events.Args(x), false)
+		case error:
+			e.Encode(x.Error())
+			b.Truncate(b.Len() - 1) // remove trailing '\n'
+		default:
+			if top {
+				// avoids allocating a pointer to v, the top-level values were
+				// already resolved
+				e.Encode(&args[i].Value)
+			} else {
+				e.Encode(v)
+			}
+			b.Truncate(b.Len() - 1) // remove trailing '\n'
+		}
+
 		n++
 	}
 
 	b.WriteByte('}')
-	return b.Bytes(), nil
 }
 
-func (data *eventData) next(i int) int {
-	for _, a := range data.args[i:] {
-		if _, ok := a.Value.(error); !ok {
-			break
+// encodeValue writes the JSON representation of v to b, values of kinds other
+// than events.KindAny are encoded without going through the json package.
+func encodeValue(b *bytes.Buffer, e *json.Encoder, v *events.Value) {
+	switch v.Kind() {
+	case events.KindBool:
+		b.Write(strconv.AppendBool(b.AvailableBuffer(), v.Bool()))
+	case events.KindDuration:
+		b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(v.Duration()), 10))
+	case events.KindInt64:
+		b.Write(strconv.AppendInt(b.AvailableBuffer(), v.Int64(), 10))
+	case events.KindUint64:
+		b.Write(strconv.AppendUint(b.AvailableBuffer(), v.Uint64(), 10))
+	case events.KindFloat64:
+		b.Write(appendJSONFloat(b.AvailableBuffer(), v.Float64()))
+	case events.KindString:
+		b.Write(appendJSONString(b.AvailableBuffer(), v.String()))
+	case events.KindTime:
+		b.Write(v.Time().AppendFormat(b.AvailableBuffer(), `"`+time.RFC3339Nano+`"`))
+	default:
+		if err, ok := v.Any().(error); ok {
+			e.Encode(err.Error())
+		} else {
+			e.Encode(v.Any())
 		}
-		i++
+		b.Truncate(b.Len() - 1) // remove trailing '\n'
+	}
+}
+
+// appendJSONFloat follows the encoding of floats by the json package, which
+// doesn't support infinities and NaN, those are encoded as null.
+func appendJSONFloat(b []byte, f float64) []byte {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return append(b, "null"...)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	n := len(b)
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if m := len(b) - n; m >= 4 && b[len(b)-4] == 'e' && b[len(b)-3] == '-' && b[len(b)-2] == '0' {
+			b[len(b)-2] = b[len(b)-1]
+			b = b[:len(b)-1]
+		}
+	}
+
+	return b
+}
+
+// appendJSONString writes s as a JSON string to b, without escaping HTML
+// characters (like the handler's encoder).
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
 	}
-	return i
+
+	return append(b, '"')
 }
 
 type stackTracer interface {
@@ -214,6 +494,8 @@ type formatter struct {
 	info    eventInfo
 	data    eventData
 	message string
+	args    events.Args
+	stack   stackTrace
 
 	buffer  buffer
 	source  buffer
@@ -239,6 +521,17 @@ var fmtPool = sync.Pool{
 	},
 }
 
+// eventStack converts the program counters of an event stack to a stack trace,
+// the returned value is only valid until the formatter is released.
+func (f *formatter) eventStack(pcs []uintptr) stackTrace {
+	if len(f.stack) == 0 {
+		for _, pc := range pcs {
+			f.stack = append(f.stack, errors.Frame(pc))
+		}
+	}
+	return f.stack
+}
+
 // This buffer type is used as an optimization, it's faster than the standard
 // bytes.Buffer because it doesn't expose such a rich API.
 type buffer struct {
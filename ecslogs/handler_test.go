@@ -1,8 +1,17 @@
 package ecslogs
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
 	"io"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -53,6 +62,215 @@ func TestHandler(t *testing.T) {
 	})
 }
 
+func TestHandlerFlush(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := bufio.NewWriter(b)
+	h := NewHandler(w)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	if b.Len() != 0 {
+		t.Fatal("the output should be buffered")
+	}
+
+	if err := events.FlushHandler(context.Background(), h); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := b.String(); s != `{"level":"INFO","time":"2017-01-01T23:42:00.123457Z","info":{},"data":{},"message":"Hello Luke!"}`+"\n" {
+		t.Error(s)
+	}
+}
+
+func TestHandlerLevel(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+		Level:   events.LevelWarn,
+	})
+
+	if s := b.String(); s != `{"level":"WARN","time":"2017-01-01T23:42:00.123457Z","info":{},"data":{},"message":"Hello Luke!"}`+"\n" {
+		t.Error(s)
+	}
+}
+
+func TestHandlerValues(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	n := events.Int(42)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "int", Value: &n},
+			{Name: "uint", Value: events.Uint64(1)},
+			{Name: "float", Value: events.Float64(0.5)},
+			{Name: "nan", Value: events.Float64(math.NaN())},
+			{Name: "bool", Value: events.Bool(true)},
+			{Name: "string", Value: events.String("\"Luke\"\n")},
+			{Name: "duration", Value: events.Duration(time.Second)},
+			{Name: "time", Value: events.Time(time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC))},
+			{Name: "any", Value: events.Any([]int{1, 2})},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	if s := b.String(); s != `{"level":"INFO","time":"2017-01-01T23:42:00.123457Z","info":{},"data":{"int":42,"uint":1,"float":0.5,"nan":null,"bool":true,"string":"\"Luke\"\n","duration":1000000000,"time":"2017-01-01T23:42:00Z","any":[1,2]},"message":"Hello Luke!"}`+"\n" {
+		t.Error(s)
+	}
+}
+
+func TestHandlerValueError(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	v := events.Any(io.EOF)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "error", Value: events.Any(io.ErrUnexpectedEOF)},
+			{Name: "cause", Value: &v},
+			{Name: "db", Value: events.Group{{Name: "error", Value: events.Any(io.EOF)}}},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	const ref = `{"level":"ERROR","time":"2017-01-01T23:42:00.123457Z","info":{"errors":[` +
+		`{"type":"*errors.errorString","error":"unexpected EOF"},` +
+		`{"type":"*errors.errorString","error":"EOF"},` +
+		`{"type":"*errors.errorString","error":"EOF"}]},` +
+		`"data":{"db":{"error":"EOF"}},"message":"Hello Luke!"}` + "\n"
+
+	if s := b.String(); s != ref {
+		t.Error(s)
+	}
+}
+
+func TestHandlerLazy(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "name", Value: events.Lazy(func() interface{} { return "Luke" })},
+			{Name: "error", Value: events.Lazy(func() interface{} { return io.EOF })},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	if s := b.String(); s != `{"level":"INFO","time":"2017-01-01T23:42:00.123457Z","info":{},"data":{"name":"Luke","error":"EOF"},"message":"Hello Luke!"}`+"\n" {
+		t.Error(s)
+	}
+}
+
+func TestHandlerGroup(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "id", Value: 1},
+			{Name: "db", Value: events.Group{
+				{Name: "id", Value: events.Int(2)},
+				{Name: "error", Value: io.EOF},
+				{Name: "query", Value: events.Group{{Name: "table", Value: "users"}}},
+			}},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	if s := b.String(); s != `{"level":"ERROR","time":"2017-01-01T23:42:00.123457Z","info":{"errors":[{"type":"*errors.errorString","error":"EOF"}]},"data":{"id":1,"db":{"id":2,"error":"EOF","query":{"table":"users"}}},"message":"Hello Luke!"}`+"\n" {
+		t.Error(s)
+	}
+}
+
+func TestHandlerGroupError(t *testing.T) {
+	b := &bytes.Buffer{}
+	l := events.NewLogger(NewHandler(b))
+	l.EnableStackOnError = true
+
+	l.WithGroup("db").Log("query failed: %{error}v", io.EOF)
+	s := b.String()
+
+	if !strings.HasPrefix(s, `{"level":"ERROR",`) {
+		t.Error("events with grouped errors must be errors:", s)
+	}
+
+	if !strings.Contains(s, `"errors":[{"type":"*errors.errorString","error":"EOF","stack":["`) {
+		t.Error("grouped errors must be reported with the stack of the event:", s)
+	}
+
+	if !strings.Contains(s, `"data":{"db":{"error":"EOF"}}`) {
+		t.Error("grouped errors must remain in their group:", s)
+	}
+}
+
+func TestHandlerEventStack(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "error", Value: io.EOF}, {Name: "cause", Value: errors.WithStack(io.EOF)}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+		Stack:   pcs[:],
+	})
+
+	file, line := events.SourceForPC(pcs[0])
+	stack := fmt.Sprintf(`"stack":["%s:%d:ecslogs.TestHandlerEventStack"]`, file, line)
+
+	if s := b.String(); strings.Count(s, `"stack":`) != 2 || !strings.Contains(s, `{"type":"*errors.errorString","error":"EOF",`+stack+`}`) {
+		t.Error(s)
+	}
+}
+
+func TestHandlerErrorTree(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "/tmp/x", Err: syscall.ENOENT}
+	joined := stderrors.Join(fmt.Errorf("reading config: %w", pathErr), io.EOF)
+
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "error", Value: fmt.Errorf("loading: %w", joined)}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	const ref = `{"level":"ERROR","time":"2017-01-01T23:42:00.123457Z","info":{"errors":[` +
+		`{"type":"*errors.joinError","error":"loading: reading config: open /tmp/x: no such file or directory\nEOF","errno":2,"causes":[` +
+		`{"type":"*errors.joinError","error":"reading config: open /tmp/x: no such file or directory\nEOF","errno":2,"causes":[` +
+		`{"type":"*fmt.wrapError","error":"reading config: open /tmp/x: no such file or directory","errno":2,"causes":[` +
+		`{"type":"*fs.PathError","error":"open /tmp/x: no such file or directory","errno":2,"causes":[` +
+		`{"type":"syscall.Errno","error":"no such file or directory","errno":2}]}]},` +
+		`{"type":"*errors.errorString","error":"EOF"}]}]}]},"data":{},"message":"Hello Luke!"}` + "\n"
+
+	if s := b.String(); s != ref {
+		t.Error("bad event:")
+		t.Logf("expected:%q", ref)
+		t.Logf("found   :%q", s)
+	}
+}
+
+func TestHandlerErrorStack(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", errors.New("oops!"))
+
+	b := &bytes.Buffer{}
+	h := NewHandler(b)
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "error", Value: err}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC),
+	})
+
+	if s := b.String(); !strings.Contains(s, `{"type":"*errors.fundamental","error":"wrapped: oops!","stack":["`) {
+		t.Error("the stack of wrapped errors must be reported:", s)
+	}
+}
+
 func BenchmarkHandler(b *testing.B) {
 	h := NewHandler(io.Discard)
 	e := &events.Event{
@@ -14,6 +14,12 @@ type Event struct {
 	// Source represents the location where this event was generated from.
 	Source string
 
+	// Format is the format string that the message was produced from, it
+	// identifies events produced by the same call site regardless of the
+	// values they were formatted with. It is empty if the event was not
+	// produced by a Logger.
+	Format string
+
 	// Args is the list of arguments of the event, it is intended to give
 	// context about the information carried by the even in a format that can
 	// be processed by a program.
@@ -24,6 +30,36 @@ type Event struct {
 
 	// Debug is set to true if this is a debugging event.
 	Debug bool
+
+	// Level is the severity level of the event. It is usually left unset, in
+	// which case the level is inferred from the other fields of the event, see
+	// the Severity method.
+	Level Level
+
+	// Stack holds the program counters of the stack frames of the call site
+	// which produced the event, from the innermost frame. It is only captured
+	// by loggers configured to (see Logger.EnableStackOnError).
+	Stack []uintptr
+}
+
+// Severity returns the severity level of the event. If the Level field is not
+// set, the level is inferred from the event:
+// - Events with at least one argument satisfying the error interface (or a
+// Value holding one), at the top level or in a Group, are at the LevelError
+// level.
+// - Debug events are at the LevelDebug level.
+// - Other events are at the LevelInfo level.
+func (e *Event) Severity() Level {
+	switch {
+	case e.Level != LevelUnset:
+		return e.Level
+	case hasError(e.Args):
+		return LevelError
+	case e.Debug:
+		return LevelDebug
+	default:
+		return LevelInfo
+	}
 }
 
 // Clone makes a deep copy of the event, the returned value doesn't shared any
@@ -32,6 +68,8 @@ func (e *Event) Clone() *Event {
 	var a Args
 	var m []byte
 	var s []byte
+	var f []byte
+	var st []uintptr
 
 	if n := len(e.Args); n != 0 {
 		a = make(Args, n)
@@ -51,12 +89,25 @@ func (e *Event) Clone() *Event {
 		copy(s, e.Source)
 	}
 
+	if n := len(e.Format); n != 0 {
+		f = make([]byte, n)
+		copy(f, e.Format)
+	}
+
+	if n := len(e.Stack); n != 0 {
+		st = make([]uintptr, n)
+		copy(st, e.Stack)
+	}
+
 	return &Event{
 		Message: string(m),
 		Source:  string(s),
+		Format:  string(f),
 		Args:    a,
 		Time:    e.Time,
 		Debug:   e.Debug,
+		Level:   e.Level,
+		Stack:   st,
 	}
 }
 
@@ -65,12 +116,50 @@ type cloner interface {
 }
 
 func clone(v interface{}) interface{} {
+	switch x := Resolve(v).(type) {
+	case Value:
+		v = x.Any()
+	case *Value:
+		v = x.Any()
+	default:
+		v = x
+	}
+	if v == nil {
+		return nil
+	}
 	if c, ok := v.(cloner); ok {
 		return c.Clone()
 	}
 	return cloneValue(v)
 }
 
+// hasError returns true if one of args, or of the arguments of the groups they
+// hold, is an error or a Value holding an error.
+func hasError(args Args) bool {
+	for _, a := range args {
+		switch v := a.Value.(type) {
+		case error:
+			return true
+		case Group:
+			if hasError(Args(v)) {
+				return true
+			}
+		case Value:
+			if _, ok := v.any.(error); ok {
+				return true
+			}
+		case *Value:
+			if v == nil {
+				break
+			}
+			if _, ok := v.any.(error); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Args reprsents a list of event arguments.
 type Args []Arg
 
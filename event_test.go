@@ -14,6 +14,7 @@ func TestEvent(t *testing.T) {
 			Source:  "file.go:42",
 			Args:    Args{{"hello", "world"}},
 			Time:    time.Now(),
+			Stack:   []uintptr{1, 2, 3},
 		}
 		e2 := e1.Clone()
 
@@ -24,6 +25,10 @@ func TestEvent(t *testing.T) {
 		if !reflect.DeepEqual(e1, e2) {
 			t.Errorf("%#v", e2)
 		}
+
+		if &e1.Stack[0] == &e2.Stack[0] {
+			t.Error("Clone must copy the stack")
+		}
 	})
 }
 
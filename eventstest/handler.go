@@ -51,6 +51,7 @@ func (h *Handler) AssertEvents(t testing.TB, expectedEvents ...events.Event) {
 func assertEqualEvent(t testing.TB, got, expected events.Event) bool {
 	return assertEqualField(t, "Args", got.Args, expected.Args) &&
 		assertEqualField(t, "Debug", got.Debug, expected.Debug) &&
+		assertEqualField(t, "Level", got.Level, expected.Level) &&
 		assertEqualField(t, "Message", got.Message, expected.Message)
 }
 
@@ -0,0 +1,670 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter returns a handler which passes to next the events matching the filter
+// expression, and discards the others. See CompileExpr for a description of
+// the expression language.
+func Filter(expr string, next Handler) (Handler, error) {
+	x, err := CompileExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &filterHandler{expr: x, handler: next}, nil
+}
+
+type filterHandler struct {
+	expr    *Expr
+	handler Handler
+}
+
+func (f *filterHandler) HandleEvent(e *Event) {
+	if f.expr.Match(e) {
+		f.handler.HandleEvent(e)
+	}
+}
+
+func (f *filterHandler) Flush(ctx context.Context) error {
+	return FlushHandler(ctx, f.handler)
+}
+
+func (f *filterHandler) Close(ctx context.Context) error {
+	return CloseHandler(ctx, f.handler)
+}
+
+// Expr is a compiled filter expression, it is safe to use concurrently from
+// multiple goroutines.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// CompileExpr parses a filter expression and returns its compiled form.
+//
+// Expressions are made of comparisons between event fields and literal values,
+// combined with the && (and), || (or) and ! (not) operators, and parentheses.
+// For example:
+//
+//	level >= "warn" && source =~ "^github.com/acme/billing" && args.status >= 500
+//
+// The event fields are:
+// - message, the event message.
+// - source, the event source.
+// - debug, true for debug events.
+// - error, true if at least one of the event arguments is an error.
+// - level, the severity of the event (see Event.Severity), compared to level
+// names like "debug", "info", "warn" or "error".
+// - args.<name>, the value of the argument named <name>, missing arguments
+// compare as not equal to any value. Arguments nested in groups are designated
+// by the dotted path of their groups, like args.db.id.
+//
+// Literal values are double-quoted strings, numbers (like 42 or 1e3),
+// durations (like 250ms or 1m30s, compared to time.Duration values), true and
+// false.
+//
+// The comparison operators are ==, !=, <, <=, >, >=, =~ (matches a regular
+// expression) and !~ (doesn't match a regular expression). A field which is
+// not compared is true if it is set to a non-zero value.
+//
+// Matching an event against a compiled expression doesn't allocate memory,
+// unless arguments are values which need to be converted to strings (like
+// errors or fmt.Stringer).
+func CompileExpr(s string) (*Expr, error) {
+	p := &exprParser{src: s}
+	p.next()
+
+	root, err := p.parseOr()
+	if err == nil && p.tok.typ != tokEOF {
+		err = p.errorf("unexpected %s", p.tok)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expr{src: s, root: root}, nil
+}
+
+// MustCompileExpr is like CompileExpr but panics if the expression is invalid.
+func MustCompileExpr(s string) *Expr {
+	x, err := CompileExpr(s)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// Match returns true if e matches the expression.
+func (x *Expr) Match(e *Event) bool {
+	return x.root.eval(e)
+}
+
+// String returns the source of the expression.
+func (x *Expr) String() string {
+	return x.src
+}
+
+// =============================================================================
+// Evaluation
+// =============================================================================
+
+type exprKind int
+
+const (
+	kindNone exprKind = iota
+	kindBool
+	kindNumber
+	kindString
+)
+
+type exprValue struct {
+	kind exprKind
+	b    bool
+	n    float64
+	s    string
+}
+
+func (v exprValue) truth() bool {
+	switch v.kind {
+	case kindBool:
+		return v.b
+	case kindNumber:
+		return v.n != 0
+	case kindString:
+		return len(v.s) != 0
+	default:
+		return false
+	}
+}
+
+func makeExprValue(v interface{}) exprValue {
+	switch x := Resolve(v).(type) {
+	case Value:
+		return makeExprValueOf(&x)
+	case *Value:
+		return makeExprValueOf(x)
+	case bool:
+		return exprValue{kind: kindBool, b: x}
+	case string:
+		return exprValue{kind: kindString, s: x}
+	case int:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case int8:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case int16:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case int32:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case int64:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uint:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uint8:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uint16:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uint32:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uint64:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case uintptr:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case float32:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case float64:
+		return exprValue{kind: kindNumber, n: x}
+	case time.Duration:
+		return exprValue{kind: kindNumber, n: float64(x)}
+	case error:
+		return exprValue{kind: kindString, s: x.Error()}
+	case fmt.Stringer:
+		return exprValue{kind: kindString, s: x.String()}
+	default:
+		return exprValue{}
+	}
+}
+
+func makeExprValueOf(v *Value) exprValue {
+	switch v.Kind() {
+	case KindBool:
+		return exprValue{kind: kindBool, b: v.num != 0}
+	case KindDuration, KindInt64:
+		return exprValue{kind: kindNumber, n: float64(int64(v.num))}
+	case KindUint64:
+		return exprValue{kind: kindNumber, n: float64(v.num)}
+	case KindFloat64:
+		return exprValue{kind: kindNumber, n: v.Float64()}
+	case KindString:
+		return exprValue{kind: kindString, s: v.str()}
+	case KindTime:
+		return exprValue{kind: kindString, s: v.String()}
+	default:
+		return makeExprValue(v.any)
+	}
+}
+
+type exprNode interface {
+	eval(e *Event) bool
+}
+
+type exprOperand interface {
+	value(e *Event) exprValue
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n *orNode) eval(e *Event) bool { return n.left.eval(e) || n.right.eval(e) }
+
+type andNode struct{ left, right exprNode }
+
+func (n *andNode) eval(e *Event) bool { return n.left.eval(e) && n.right.eval(e) }
+
+type notNode struct{ node exprNode }
+
+func (n *notNode) eval(e *Event) bool { return !n.node.eval(e) }
+
+type truthNode struct{ operand exprOperand }
+
+func (n *truthNode) eval(e *Event) bool { return n.operand.value(e).truth() }
+
+type compareNode struct {
+	op    string
+	left  exprOperand
+	right exprOperand
+}
+
+func (n *compareNode) eval(e *Event) bool {
+	l := n.left.value(e)
+	r := n.right.value(e)
+
+	switch n.op {
+	case "==":
+		return equalValues(l, r)
+	case "!=":
+		return !equalValues(l, r)
+	}
+
+	if l.kind != r.kind {
+		return false
+	}
+
+	var c int
+	switch l.kind {
+	case kindNumber:
+		switch {
+		case l.n < r.n:
+			c = -1
+		case l.n > r.n:
+			c = +1
+		}
+	case kindString:
+		c = strings.Compare(l.s, r.s)
+	default:
+		return false
+	}
+
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+func equalValues(v1 exprValue, v2 exprValue) bool {
+	if v1.kind != v2.kind {
+		return false
+	}
+	switch v1.kind {
+	case kindBool:
+		return v1.b == v2.b
+	case kindNumber:
+		return v1.n == v2.n
+	case kindString:
+		return v1.s == v2.s
+	default:
+		return false
+	}
+}
+
+type matchNode struct {
+	left   exprOperand
+	regexp *regexp.Regexp
+	negate bool
+}
+
+func (n *matchNode) eval(e *Event) bool {
+	v := n.left.value(e)
+	if v.kind != kindString {
+		return false
+	}
+	return n.regexp.MatchString(v.s) != n.negate
+}
+
+type literal struct{ v exprValue }
+
+func (l *literal) value(e *Event) exprValue { return l.v }
+
+type messageField struct{}
+
+func (messageField) value(e *Event) exprValue { return exprValue{kind: kindString, s: e.Message} }
+
+type sourceField struct{}
+
+func (sourceField) value(e *Event) exprValue { return exprValue{kind: kindString, s: e.Source} }
+
+type debugField struct{}
+
+func (debugField) value(e *Event) exprValue { return exprValue{kind: kindBool, b: e.Debug} }
+
+type errorField struct{}
+
+func (errorField) value(e *Event) exprValue { return exprValue{kind: kindBool, b: hasError(e.Args)} }
+
+type levelField struct{}
+
+func (levelField) value(e *Event) exprValue {
+	return exprValue{kind: kindNumber, n: float64(e.Severity())}
+}
+
+type argField struct{ name string }
+
+func (f *argField) value(e *Event) exprValue {
+	if v, ok := lookupArg(e.Args, f.name); ok {
+		return makeExprValue(v)
+	}
+	return exprValue{}
+}
+
+// lookupArg returns the value of the argument named name in args, dotted names
+// designate arguments nested in groups when no arguments have the full name.
+func lookupArg(args Args, name string) (interface{}, bool) {
+	if v, ok := args.Get(name); ok {
+		return v, true
+	}
+
+	for i := 0; i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+		if v, ok := args.Get(name[:i]); ok {
+			if g, ok := Resolve(v).(Group); ok {
+				if v, ok := lookupArg(Args(g), name[i+1:]); ok {
+					return v, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// =============================================================================
+// Parsing
+// =============================================================================
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOperator
+	tokInvalid
+)
+
+type token struct {
+	typ tokenType
+	pos int
+	s   string
+	v   exprValue
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokEOF:
+		return "end of expression"
+	case tokInvalid:
+		return t.s
+	}
+	return strconv.Quote(t.s)
+}
+
+type exprParser struct {
+	src string
+	off int
+	tok token
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("events: invalid filter expression %q at offset %d: %s", p.src, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.tok.typ == tokOperator && p.tok.s == "||" {
+		var right exprNode
+		p.next()
+		if right, err = p.parseAnd(); err == nil {
+			left = &orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.tok.typ == tokOperator && p.tok.s == "&&" {
+		var right exprNode
+		p.next()
+		if right, err = p.parseUnary(); err == nil {
+			left = &andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.tok.typ != tokOperator {
+		return p.parseComparison()
+	}
+
+	switch p.tok.s {
+	case "!":
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node}, nil
+
+	case "(":
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.typ != tokOperator || p.tok.s != ")" {
+			return nil, p.errorf("expected \")\" but found %s", p.tok)
+		}
+		p.next()
+		return node, nil
+	}
+
+	return nil, p.errorf("unexpected %s", p.tok)
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.typ != tokOperator {
+		return &truthNode{left}, nil
+	}
+
+	switch op := p.tok.s; op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.levelOperand(left, right); err != nil {
+			return nil, err
+		}
+		if right, err = p.levelOperand(right, left); err != nil {
+			return nil, err
+		}
+		return &compareNode{op: op, left: left, right: right}, nil
+
+	case "=~", "!~":
+		p.next()
+		if p.tok.typ != tokString {
+			return nil, p.errorf("expected a regular expression but found %s", p.tok)
+		}
+		re, err := regexp.Compile(p.tok.v.s)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		p.next()
+		return &matchNode{left: left, regexp: re, negate: op == "!~"}, nil
+
+	default:
+		return &truthNode{left}, nil
+	}
+}
+
+// levelOperand converts operand to a numeric level if it is a string literal
+// compared to the level field.
+func (p *exprParser) levelOperand(operand, other exprOperand) (exprOperand, error) {
+	lit, ok := operand.(*literal)
+	if !ok || lit.v.kind != kindString {
+		return operand, nil
+	}
+	if _, ok := other.(levelField); !ok {
+		return operand, nil
+	}
+	level, err := ParseLevel(lit.v.s)
+	if err != nil {
+		return nil, p.errorf("unknown level %q", lit.v.s)
+	}
+	return &literal{exprValue{kind: kindNumber, n: float64(level)}}, nil
+}
+
+func (p *exprParser) parseOperand() (exprOperand, error) {
+	tok := p.tok
+
+	switch tok.typ {
+	case tokString, tokNumber:
+		p.next()
+		return &literal{tok.v}, nil
+
+	case tokIdent:
+		p.next()
+		switch tok.s {
+		case "true":
+			return &literal{exprValue{kind: kindBool, b: true}}, nil
+		case "false":
+			return &literal{exprValue{kind: kindBool, b: false}}, nil
+		case "message":
+			return messageField{}, nil
+		case "source":
+			return sourceField{}, nil
+		case "debug":
+			return debugField{}, nil
+		case "error":
+			return errorField{}, nil
+		case "level":
+			return levelField{}, nil
+		}
+		if name := strings.TrimPrefix(tok.s, "args."); name != tok.s && len(name) != 0 {
+			return &argField{name}, nil
+		}
+		p.tok = tok
+		return nil, p.errorf("unknown field %s", tok)
+
+	case tokInvalid:
+		return nil, p.errorf("%s", tok.s)
+	}
+
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *exprParser) next() {
+	s := p.src
+
+	for p.off < len(s) && isSpace(s[p.off]) {
+		p.off++
+	}
+
+	pos := p.off
+
+	if pos == len(s) {
+		p.tok = token{typ: tokEOF, pos: pos}
+		return
+	}
+
+	switch c := s[pos]; {
+	case c == '"':
+		i := pos + 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			p.tok = token{typ: tokInvalid, pos: pos, s: "unterminated string"}
+			p.off = len(s)
+			return
+		}
+		lit := s[pos : i+1]
+		str, err := strconv.Unquote(lit)
+		if err != nil {
+			p.tok = token{typ: tokInvalid, pos: pos, s: "invalid string " + lit}
+		} else {
+			p.tok = token{typ: tokString, pos: pos, s: lit, v: exprValue{kind: kindString, s: str}}
+		}
+		p.off = i + 1
+
+	case isDigit(c) || (c == '-' && pos+1 < len(s) && isDigit(s[pos+1])):
+		i := scanNumber(s, pos+1)
+		lit := s[pos:i]
+		p.off = i
+
+		if f, err := strconv.ParseFloat(lit, 64); err == nil {
+			p.tok = token{typ: tokNumber, pos: pos, s: lit, v: exprValue{kind: kindNumber, n: f}}
+		} else if d, err := time.ParseDuration(lit); err == nil {
+			p.tok = token{typ: tokNumber, pos: pos, s: lit, v: exprValue{kind: kindNumber, n: float64(d)}}
+		} else if strings.IndexFunc(lit, isNotNumber) >= 0 {
+			p.tok = token{typ: tokInvalid, pos: pos, s: "invalid duration " + lit}
+		} else {
+			p.tok = token{typ: tokInvalid, pos: pos, s: "invalid number " + lit}
+		}
+
+	case isLetter(c) || c == '_':
+		i := pos + 1
+		for i < len(s) && (isLetter(s[i]) || isDigit(s[i]) || s[i] == '_' || s[i] == '.' || s[i] == '-') {
+			i++
+		}
+		p.tok = token{typ: tokIdent, pos: pos, s: s[pos:i]}
+		p.off = i
+
+	default:
+		for _, op := range [...]string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "!", "<", ">", "(", ")"} {
+			if strings.HasPrefix(s[pos:], op) {
+				p.tok = token{typ: tokOperator, pos: pos, s: op}
+				p.off = pos + len(op)
+				return
+			}
+		}
+		p.tok = token{typ: tokInvalid, pos: pos, s: fmt.Sprintf("invalid character %q", c)}
+		p.off = len(s)
+	}
+}
+
+// scanNumber returns the end of the number or duration literal starting before
+// i in s. Numbers and durations are scanned together since durations alternate
+// digits and units (e.g. 1m30s), the sign of exponents is also part of the
+// literal (e.g. 1e-3).
+func scanNumber(s string, i int) int {
+	for i < len(s) {
+		switch c := s[i]; {
+		case isDigit(c) || c == '.' || isLetter(c) || c == 0xC2 || c == 0xB5: // 'µ' is encoded as 0xC2 0xB5
+		case (c == '-' || c == '+') && (s[i-1] == 'e' || s[i-1] == 'E'):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+func isNotNumber(r rune) bool {
+	return r != '-' && r != '+' && r != '.' && r != 'e' && r != 'E' && (r < '0' || r > '9')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var filterEvent = &Event{
+	Message: "Hello Luke!",
+	Source:  "github.com/acme/billing/handler.go:42",
+	Args: Args{
+		{"name", "Luke"},
+		{"status", 503},
+		{"ratio", 0.5},
+		{"cached", true},
+		{"duration", 300 * time.Millisecond},
+		{"error", errors.New("oops!")},
+		{"db", Group{{"id", 42}, {"query", Group{{"table", "users"}}}}},
+		{"http.method", "GET"},
+	},
+}
+
+func TestFilterExpr(t *testing.T) {
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{`debug`, false},
+		{`!debug`, true},
+		{`debug == false`, true},
+		{`error`, true},
+		{`message == "Hello Luke!"`, true},
+		{`message != "Hello Luke!"`, false},
+		{`source =~ "^github.com/acme/billing"`, true},
+		{`source !~ "^github.com/acme/billing"`, false},
+		{`args.status >= 500`, true},
+		{`args.status < 500`, false},
+		{`args.status == 503 && args.name == "Luke"`, true},
+		{`args.status == 200 || args.name == "Luke"`, true},
+		{`args.status == 200 || (args.name == "Han" && !debug)`, false},
+		{`args.ratio > 0.25`, true},
+		{`args.cached`, true},
+		{`args.cached == false`, false},
+		{`args.duration > 250ms`, true},
+		{`args.duration > 1s`, false},
+		{`args.duration < 1m30s`, true},
+		{`args.duration > 0.1s`, true},
+		{`args.status < 1e3`, true},
+		{`args.ratio >= 25e-2`, true},
+		{`args.status>=500&&args.duration<1m30s`, true},
+		{`args.error == "oops!"`, true},
+		{`args.db.id == 42`, true},
+		{`args.db.query.table == "users"`, true},
+		{`args.db.table == "users"`, false},
+		{`args.http.method == "GET"`, true},
+		{`args.missing`, false},
+		{`args.missing == 1`, false},
+		{`args.missing != 1`, true},
+		{`args.name > 1`, false},
+		{`level == "error"`, true},
+		{`level >= "warn"`, true},
+		{`"info" >= level`, false},
+		{`level < 4`, false},
+		{`debug == false && source =~ "^github.com/acme/billing" && args.status >= 500`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			x, err := CompileExpr(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match := x.Match(filterEvent); match != test.match {
+				t.Error("bad match:", match)
+			}
+		})
+	}
+}
+
+func TestFilterExprError(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`message ==`,
+		`(debug`,
+		`debug)`,
+		`unknown`,
+		`args.`,
+		`message =~ 42`,
+		`message =~ "("`,
+		`message == "hello`,
+		`args.duration > 1y`,
+		`args.duration > 1m30`,
+		`args.status > 1e`,
+		`debug & error`,
+		`level == "fatal"`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := CompileExpr(expr); err == nil {
+				t.Error("expected an error")
+			} else {
+				t.Log(err)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	n := 0
+	h, err := Filter(`args.status >= 500`, HandlerFunc(func(e *Event) { n++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.HandleEvent(&Event{Args: Args{{"status", 200}}})
+	h.HandleEvent(&Event{Args: Args{{"status", 500}}})
+
+	if n != 1 {
+		t.Error("bad count of events passed by the filter:", n)
+	}
+}
+
+func TestFilterAllocs(t *testing.T) {
+	x := MustCompileExpr(`debug == false && source =~ "^github.com/acme/billing" && args.status >= 500`)
+
+	if n := testing.AllocsPerRun(100, func() { x.Match(filterEvent) }); n != 0 {
+		t.Error("matching an event allocated memory:", n)
+	}
+}
+
+func BenchmarkFilterExpr(b *testing.B) {
+	x := MustCompileExpr(`debug == false && source =~ "^github.com/acme/billing" && args.status >= 500`)
+
+	for i := 0; i != b.N; i++ {
+		x.Match(filterEvent)
+	}
+}
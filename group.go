@@ -0,0 +1,95 @@
+package events
+
+// Group is an argument value which holds a nested list of arguments, it is
+// used to namespace arguments under the name of the argument holding the group.
+//
+// Handlers of the events sub-packages encode groups as nested objects, or name
+// the arguments after the path of the groups they belong to, joined with dots
+// (for example "db.id").
+//
+// Here's an example of an event with a group argument:
+//
+//	events.Log("query executed", events.Args{
+//		{"db", events.Group{{"id", 42}, {"table", "users"}}},
+//	})
+type Group Args
+
+// Clone makes a copy of the group and its nested groups, it satisfies the interface used by
+// Event.Clone to copy argument values.
+func (g Group) Clone() interface{} {
+	c := make(Group, len(g))
+	for i, a := range g {
+		c[i] = Arg{a.Name, clone(a.Value)}
+	}
+	return c
+}
+
+// loggerGroup represents a group opened on a logger by a call to WithGroup,
+// args are the arguments added to the group by calls to With.
+type loggerGroup struct {
+	name string
+	args Args
+}
+
+// appendGroups appends to dst the argument holding the nested groups, with args
+// added to the innermost group. Groups with no arguments are omitted.
+func appendGroups(dst Args, groups []loggerGroup, args Args) Args {
+	var child Arg
+	var hasChild bool
+
+	for i := len(groups) - 1; i >= 0; i-- {
+		g := groups[i]
+		n := len(g.args)
+
+		if hasChild {
+			n++
+		}
+
+		if i == len(groups)-1 {
+			n += len(args)
+		}
+
+		if n == 0 {
+			hasChild = false
+			continue
+		}
+
+		content := make(Group, 0, n)
+		content = append(content, g.args...)
+
+		if i == len(groups)-1 {
+			content = append(content, args...)
+		}
+
+		if hasChild {
+			content = append(content, child)
+		}
+
+		child, hasChild = Arg{g.name, content}, true
+	}
+
+	if hasChild {
+		dst = append(dst, child)
+	}
+
+	return dst
+}
+
+// setArgs appends args to dst, replacing the values of arguments of dst with
+// the same names instead of appending them.
+func setArgs(dst Args, args Args) Args {
+	for _, a := range args {
+		i := 0
+
+		for i < len(dst) && dst[i].Name != a.Name {
+			i++
+		}
+
+		if i < len(dst) {
+			dst[i].Value = a.Value
+		} else {
+			dst = append(dst, a)
+		}
+	}
+	return dst
+}
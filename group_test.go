@@ -0,0 +1,30 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupClone(t *testing.T) {
+	v := Int(42)
+	g := Group{{"id", &v}, {"tags", []string{"a"}}, {"query", Group{{"table", String("users")}}}}
+	c := g.Clone().(Group)
+
+	if !reflect.DeepEqual(c, Group{{"id", 42}, {"tags", []string{"a"}}, {"query", Group{{"table", "users"}}}}) {
+		t.Errorf("%#v", c)
+	}
+
+	c[2].Value.(Group)[0].Value = "orders"
+
+	if g[2].Value.(Group)[0].Value != String("users") {
+		t.Error("cloning a group must make copies of the nested groups")
+	}
+}
+
+func TestSetArgs(t *testing.T) {
+	args := setArgs(Args{{"a", 1}, {"b", 2}}, Args{{"b", 3}, {"c", 4}, {"c", 5}})
+
+	if !reflect.DeepEqual(args, Args{{"a", 1}, {"b", 3}, {"c", 5}}) {
+		t.Errorf("%#v", args)
+	}
+}
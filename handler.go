@@ -1,5 +1,11 @@
 package events
 
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
 // The Handler interface is implemented by types that intend to be event routers
 // or apply transformations to an event before forwarding it to another handler.
 type Handler interface {
@@ -12,6 +18,88 @@ type Handler interface {
 	HandleEvent(e *Event)
 }
 
+// The Flusher interface may be implemented by handlers that buffer events,
+// giving the program a way to wait until those events were published.
+type Flusher interface {
+	// Flush blocks until all events received by the handler were published,
+	// or ctx is canceled.
+	Flush(ctx context.Context) error
+}
+
+// The Closer interface may be implemented by handlers that hold resources which
+// need to be released when the program stops producing events.
+type Closer interface {
+	// Close publishes the events buffered by the handler and releases its
+	// resources. The handler must not be used after Close was called.
+	Close(ctx context.Context) error
+}
+
+// FlushHandler flushes handler if it implements the Flusher interface, and
+// does nothing otherwise.
+//
+// Handlers that wrap other handlers (like those returned by MultiHandler) are
+// expected to implement Flusher and flush the handlers they wrap.
+func FlushHandler(ctx context.Context, handler Handler) error {
+	if f, ok := handler.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// CloseHandler closes handler if it implements the Closer interface, or
+// flushes it if it only implements the Flusher interface.
+func CloseHandler(ctx context.Context, handler Handler) error {
+	if c, ok := handler.(Closer); ok {
+		return c.Close(ctx)
+	}
+	return FlushHandler(ctx, handler)
+}
+
+// Flush flushes the default handler, and the handler of the default logger if
+// it has one.
+//
+// Programs should call Flush before exiting to ensure that the last events
+// they produced are not lost.
+func Flush(ctx context.Context) error {
+	var errs []error
+	for _, h := range defaultHandlers() {
+		if err := FlushHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the default handler, and the handler of the default logger if
+// it has one.
+func Close(ctx context.Context) error {
+	var errs []error
+	for _, h := range defaultHandlers() {
+		if err := CloseHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func defaultHandlers() []Handler {
+	handlers := []Handler{DefaultHandler}
+
+	if h := DefaultLogger.Handler; h != nil && !sameHandler(h, DefaultHandler) {
+		handlers = append(handlers, h)
+	}
+
+	return handlers
+}
+
+func sameHandler(h1 Handler, h2 Handler) bool {
+	// Handlers may not be comparable (HandlerFunc for example), the types are
+	// checked first to avoid a runtime panic.
+	t1 := reflect.TypeOf(h1)
+	t2 := reflect.TypeOf(h2)
+	return t1 == t2 && t1.Comparable() && h1 == h2
+}
+
 // HandlerFunc makes it possible for simple function types to be used as event
 // handlers.
 type HandlerFunc func(*Event)
@@ -41,6 +129,26 @@ func (m *multiHandler) HandleEvent(e *Event) {
 	}
 }
 
+func (m *multiHandler) Flush(ctx context.Context) error {
+	var errs []error
+	for _, h := range m.handlers {
+		if err := FlushHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiHandler) Close(ctx context.Context) error {
+	var errs []error
+	for _, h := range m.handlers {
+		if err := CloseHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 var (
 	// Discard is a handler that does nothing with the events it receives.
 	Discard Handler = HandlerFunc(func(e *Event) {})
@@ -1,6 +1,9 @@
 package events
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestMultiHandler(t *testing.T) {
 	n := 0
@@ -15,3 +18,87 @@ func TestMultiHandler(t *testing.T) {
 		t.Error("bad count of handler received the event:", n)
 	}
 }
+
+type flushHandler struct {
+	flushed int
+	closed  int
+}
+
+func (h *flushHandler) HandleEvent(e *Event) {}
+
+func (h *flushHandler) Flush(ctx context.Context) error {
+	h.flushed++
+	return nil
+}
+
+type closeHandler struct {
+	flushHandler
+}
+
+func (h *closeHandler) Close(ctx context.Context) error {
+	h.closed++
+	return nil
+}
+
+func TestFlushHandler(t *testing.T) {
+	h1 := &flushHandler{}
+	h2 := &closeHandler{}
+	m := MultiHandler(h1, Discard, MultiHandler(h2))
+	ctx := context.Background()
+
+	if err := FlushHandler(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if h1.flushed != 1 || h2.flushed != 1 {
+		t.Error("handlers were not flushed:", h1.flushed, h2.flushed)
+	}
+
+	if err := CloseHandler(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if h1.flushed != 2 {
+		t.Error("handlers that don't implement Closer must be flushed:", h1.flushed)
+	}
+
+	if h2.closed != 1 || h2.flushed != 1 {
+		t.Error("handlers that implement Closer must be closed:", h2.closed, h2.flushed)
+	}
+
+	if err := FlushHandler(ctx, Discard); err != nil {
+		t.Error("flushing a handler that doesn't implement Flusher must not fail:", err)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	defaultHandler := DefaultHandler
+	defaultLoggerHandler := DefaultLogger.Handler
+	defer func() {
+		DefaultHandler = defaultHandler
+		DefaultLogger.Handler = defaultLoggerHandler
+	}()
+
+	h1 := &flushHandler{}
+	h2 := &flushHandler{}
+	DefaultHandler = h1
+	DefaultLogger.Handler = h1
+
+	if err := Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if h1.flushed != 1 {
+		t.Error("the default handler must be flushed once:", h1.flushed)
+	}
+
+	DefaultLogger.Handler = h2
+
+	if err := Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if h1.flushed != 2 || h2.flushed != 1 {
+		t.Error("the default handlers were not flushed:", h1.flushed, h2.flushed)
+	}
+}
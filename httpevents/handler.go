@@ -2,9 +2,11 @@ package httpevents
 
 import (
 	"bufio"
+	"encoding/hex"
 	"net"
 	"net/http"
 	"sync"
+	"unsafe"
 
 	"github.com/segmentio/events/v2"
 )
@@ -26,9 +28,22 @@ func NewHandlerWith(logger *events.Logger, handler http.Handler) http.Handler {
 // requests with logger.  The LogSanitizer is used to sanitize the request and response that is logged.  eg, to
 // remove PII.
 //
+// The W3C trace context propagated by the traceparent and tracestate headers of
+// requests is parsed, and a child span is created for the request (a new trace
+// is started if the request had no valid trace context). The trace_id and
+// span_id of the request are reported in the access log.
+//
+// The context of requests passed to handler carries the trace context (see
+// TraceContextFromContext), logger (unless it already carried one), and the
+// remote_address, method, path, trace_id and span_id arguments, so events
+// logged with the context-aware functions of the events package are augmented
+// with these request-scoped fields (see events.LogContext).
+//
 // Panics from handler are intercepted and trigger a 500 response if no response
-// header was sent yet. The panic is not silenced tho and is propagated to the
-// parent handler.
+// header was sent yet, the access log then has a "panic" argument holding an
+// *events.PanicError which carries the panic value and the stack of the
+// handler. The panic is not silenced tho and is propagated to the parent
+// handler.
 func NewHandlerWithSanitizer(sanitizer LogSanitizer, logger *events.Logger, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		var laddr string
@@ -44,6 +59,10 @@ func NewHandlerWithSanitizer(sanitizer LogSanitizer, logger *events.Logger, hand
 		w.logger = logger
 		w.request.reset(sanitizer, req, laddr)
 
+		rc := newRequestContext(req, sanitizer)
+		w.request.traceID = &rc.lazy[1]
+		w.request.spanID = &rc.lazy[2]
+
 		// If the handler panics we want to make sure we report the issue in the
 		// access log, while also ensuring that a response is going to be sent
 		// down to the client.
@@ -52,6 +71,7 @@ func NewHandlerWithSanitizer(sanitizer LogSanitizer, logger *events.Logger, hand
 		defer func() {
 			err := recover()
 			if err != nil {
+				w.request.panic = events.NewPanicError(err)
 				w.WriteHeader(http.StatusInternalServerError)
 			}
 
@@ -70,11 +90,112 @@ func NewHandlerWithSanitizer(sanitizer LogSanitizer, logger *events.Logger, hand
 		// writer's WriteHeader method we force the call with "200 OK" status
 		// to match the default behavior of the net/http package (and also make
 		// sure an access log will be written).
-		handler.ServeHTTP(w, req)
+		handler.ServeHTTP(w, withContext(req, logger, rc))
 		w.WriteHeader(http.StatusOK)
 	})
 }
 
+// withContext returns a shallow copy of req with rc as context, carrying logger
+// unless the context of req already carried one.
+func withContext(req *http.Request, logger *events.Logger, rc *requestContext) *http.Request {
+	ctx := req.Context()
+
+	if logger != nil && events.LoggerFromContext(ctx) == events.DefaultLogger {
+		rc.Logger = logger
+	}
+
+	rc.Context = ctx
+	rc.Appender = rc
+	return req.WithContext(rc)
+}
+
+// requestContext is the context of requests passed to handlers, it carries the
+// logger, the trace context and the request-scoped arguments of events, so a
+// single object is allocated for each request.
+//
+// The values of the arguments which are costly to produce (the sanitized path
+// and the hexadecimal representation of the trace identifiers) are only
+// computed when an event carrying them is encoded, requests that don't log
+// anything don't pay for them.
+type requestContext struct {
+	events.ArgsContext
+	tc        TraceContext
+	path      string
+	sanitizer PathSanitizer
+
+	once   sync.Once
+	lazy   [3]requestValue // path, trace_id and span_id
+	values [5]events.Value
+	ids    [2*len(TraceContext{}.TraceID) + 2*len(TraceContext{}.SpanID)]byte
+}
+
+func newRequestContext(req *http.Request, sanitizer LogSanitizer) *requestContext {
+	tc, ok := TraceContextFromRequest(req)
+	if ok {
+		tc = tc.NewChild()
+	} else {
+		tc = NewTraceContext()
+	}
+
+	rc := &requestContext{
+		tc:        tc,
+		path:      req.URL.Path,
+		sanitizer: sanitizer.Path,
+	}
+	rc.values[0] = events.String(req.RemoteAddr)
+	rc.values[1] = events.String(req.Method)
+
+	for i := range rc.lazy {
+		rc.lazy[i] = requestValue{rc: rc, index: 2 + i}
+	}
+
+	return rc
+}
+
+// init computes the values of the lazy arguments. The identifiers are
+// hex-encoded in the buffer of the request context, which is never modified
+// afterwards, so their strings don't need to be allocated.
+func (rc *requestContext) init() {
+	n := hex.Encode(rc.ids[:], rc.tc.TraceID[:])
+	hex.Encode(rc.ids[n:], rc.tc.SpanID[:])
+
+	rc.values[2] = events.String(rc.sanitizer(rc.path))
+	rc.values[3] = events.String(unsafe.String(&rc.ids[0], n))
+	rc.values[4] = events.String(unsafe.String(&rc.ids[n], len(rc.ids)-n))
+}
+
+// Value satisfies the context.Context interface.
+func (rc *requestContext) Value(key interface{}) interface{} {
+	if key == (traceContextKey{}) {
+		return rc.tc
+	}
+	return rc.ArgsContext.Value(key)
+}
+
+// AppendArgs satisfies the events.ArgsAppender interface.
+func (rc *requestContext) AppendArgs(args events.Args) events.Args {
+	return append(args,
+		events.Arg{Name: "remote_address", Value: &rc.values[0]},
+		events.Arg{Name: "method", Value: &rc.values[1]},
+		events.Arg{Name: "path", Value: &rc.lazy[0]},
+		events.Arg{Name: "trace_id", Value: &rc.lazy[1]},
+		events.Arg{Name: "span_id", Value: &rc.lazy[2]},
+	)
+}
+
+// requestValue is the value of a request-scoped argument, it implements the
+// events.Valuer interface so the value is only computed when it is encoded.
+type requestValue struct {
+	rc    *requestContext
+	index int
+}
+
+// EventValue satisfies the events.Valuer interface.
+func (v *requestValue) EventValue() interface{} {
+	v.rc.once.Do(v.rc.init)
+	return &v.rc.values[v.index]
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	logger *events.Logger
@@ -115,5 +236,5 @@ func (w *responseWriter) log(depth int, status int) {
 }
 
 var responseWriterPool = sync.Pool{
-	New: func() interface{} { return &responseWriter{} },
+	New: func() interface{} { return &responseWriter{request: *newRequest()} },
 }
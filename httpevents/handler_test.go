@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/segmentio/events/v2"
@@ -73,6 +75,8 @@ func TestHandler(t *testing.T) {
 			{Name: "query", Value: "answer=42"},
 			{Name: "fragment", Value: "universe"},
 			{Name: "status", Value: 202},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
 			{Name: "request", Value: &headerList{{name: "User-Agent", value: "httpevents"}}},
 			{Name: "response", Value: &headerList{}},
 		},
@@ -80,6 +84,38 @@ func TestHandler(t *testing.T) {
 	})
 }
 
+func TestHandlerContext(t *testing.T) {
+	eventsHandler := &eventstest.Handler{}
+
+	req := httptest.NewRequest("POST", "/users/1234", nil)
+	req.RemoteAddr = "127.0.0.1:56789"
+
+	log := events.NewLogger(eventsHandler)
+	log.EnableDebug = false
+
+	sanitizer := DefaultLogSanitizer.WithPathSanitizer(func(string) string { return "/users/:id" })
+
+	h := NewHandlerWithSanitizer(sanitizer, log, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if logger := events.LoggerFromContext(req.Context()); logger != log {
+			t.Error("the request context should carry the handler's logger")
+		}
+		events.LogContext(req.Context(), "Hello %{name}s!", "Luke")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	eventsHandler.AssertEvents(t, events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "remote_address", Value: "127.0.0.1:56789"},
+			{Name: "method", Value: "POST"},
+			{Name: "path", Value: "/users/:id"},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
+			{Name: "name", Value: "Luke"},
+		},
+	})
+}
+
 func TestNewHandlerWithSanitizer(t *testing.T) {
 	eventsHandler := &eventstest.Handler{}
 
@@ -137,6 +173,8 @@ func TestNewHandlerWithSanitizer(t *testing.T) {
 			{Name: "query", Value: "<REDACTED>"},
 			{Name: "fragment", Value: "universe"},
 			{Name: "status", Value: 202},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
 			{Name: "request", Value: &headerList{{name: "User-Agent", value: "httpevents"}}},
 			{Name: "response", Value: &headerList{{name: "Safe", value: "this header does not contain PII"}}},
 		},
@@ -201,6 +239,8 @@ func TestNewHandlerWithSanitizerOtherBehavior(t *testing.T) {
 			{Name: "path", Value: "/abc/123/<REDACTED>"},
 			{Name: "fragment", Value: "universe"},
 			{Name: "status", Value: 202},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
 			{Name: "request", Value: &headerList{{name: "User-Agent", value: "httpevents"}}},
 			{Name: "response", Value: &headerList{{name: "Safe", value: "this header does not contain PII"}}},
 		},
@@ -221,7 +261,20 @@ func TestHandlerPanic(t *testing.T) {
 	}))
 
 	res := httptest.NewRecorder()
-	log := events.NewLogger(eventsHandler)
+	var stack []uintptr
+
+	// The stack of the panic can't be predicted, it is removed from the events
+	// and checked separately.
+	log := events.NewLogger(events.HandlerFunc(func(e *events.Event) {
+		e = e.Clone()
+		for i, a := range e.Args {
+			if p, ok := a.Value.(*events.PanicError); ok {
+				stack = p.Stack
+				e.Args[i].Value = &events.PanicError{Value: p.Value}
+			}
+		}
+		eventsHandler.HandleEvent(e)
+	}))
 
 	h := NewHandlerWith(log, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		panic("bye bye!")
@@ -241,10 +294,19 @@ func TestHandlerPanic(t *testing.T) {
 			{Name: "method", Value: "POST"},
 			{Name: "path", Value: "/"},
 			{Name: "status", Value: 500},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
 			{Name: "request", Value: &headerList{{name: "User-Agent", value: "httpevents"}}},
 			{Name: "response", Value: &headerList{}},
+			{Name: "panic", Value: &events.PanicError{Value: "bye bye!"}},
 		},
 	})
+
+	if len(stack) == 0 {
+		t.Error("the stack of the panic must be reported")
+	} else if fn := runtime.FuncForPC(stack[0] - 1).Name(); !strings.Contains(fn, "TestHandlerPanic") {
+		t.Error("the stack must start at the function which panicked:", fn)
+	}
 }
 
 func BenchmarkHandler(b *testing.B) {
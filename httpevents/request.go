@@ -23,13 +23,22 @@ type request struct {
 	agent      string
 	reqHeaders headerList
 	resHeaders headerList
-	extraArgs  events.Args
 	status     int
 	statusText string
-	fmtbuf     []byte
-	logbuf     []byte
-	argbuf     []interface{}
+	traceID    interface{} // argument values of the trace identifiers
+	spanID     interface{}
+	traceIDs   [2]events.Value
+	panic      *events.PanicError
 	sanitizer  LogSanitizer
+
+	// The values, argument list and extra arguments are retained across uses
+	// of the request so that logging doesn't cause memory allocations: the
+	// arguments are pointers to the values, and extraArgs has a fixed length
+	// so its backing array is shared with the copy boxed in extra.
+	values    [10]events.Value
+	argbuf    []interface{}
+	extraArgs events.Args
+	extra     interface{}
 }
 
 func acquireRequest(sanitizer LogSanitizer, req *http.Request, laddr string) *request {
@@ -57,12 +66,21 @@ func (r *request) release() {
 	r.agent = zero
 	r.status = 0
 	r.statusText = zero
+	r.traceID = nil
+	r.spanID = nil
+	r.traceIDs = [len(r.traceIDs)]events.Value{}
+	r.panic = nil
 	r.reqHeaders.clear()
 	r.resHeaders.clear()
+	r.values = [len(r.values)]events.Value{}
 
 	for i := range r.argbuf {
 		r.argbuf[i] = nil
 	}
+
+	for i := range r.extraArgs {
+		r.extraArgs[i] = events.Arg{}
+	}
 }
 
 func (r *request) reset(sanitizer LogSanitizer, req *http.Request, laddr string) {
@@ -88,47 +106,66 @@ func (r *request) reset(sanitizer LogSanitizer, req *http.Request, laddr string)
 	r.reqHeaders.set(sanitizer.ReqHeaders(req.Header))
 }
 
+// setTrace sets the trace and span identifiers reported in the log of r.
+func (r *request) setTrace(traceID, spanID string) {
+	r.traceIDs = [...]events.Value{events.String(traceID), events.String(spanID)}
+	r.traceID = &r.traceIDs[0]
+	r.spanID = &r.traceIDs[1]
+}
+
 func (r *request) log(logger *events.Logger, resHeader http.Header, depth int) {
 	r.resHeaders.set(r.sanitizer.ResHeaders(resHeader))
-	r.extraArgs = append(r.extraArgs[:0],
-		events.Arg{
-			Name:  "request",
-			Value: &r.reqHeaders,
-		},
-		events.Arg{
-			Name:  "response",
-			Value: &r.resHeaders,
-		},
+	r.extraArgs[0] = events.Arg{Name: "trace_id", Value: r.traceID}
+	r.extraArgs[1] = events.Arg{Name: "span_id", Value: r.spanID}
+	r.extraArgs[2] = events.Arg{Name: "request", Value: &r.reqHeaders}
+	r.extraArgs[3] = events.Arg{Name: "response", Value: &r.resHeaders}
+
+	v := append(r.values[:0],
+		events.String(r.laddr),
+		events.String(r.raddr),
+		events.String(r.host),
+		events.String(r.method),
 	)
-
-	arg := append(r.argbuf[:0], convS2E(&r.laddr), convS2E(&r.raddr), convS2E(&r.host), convS2E(&r.method))
-	fmt := append(r.logbuf[:0], "%{local_address}s->%{remote_address}s - %{host}s - %{method}s"...)
+	f := 0
 
 	// Some methods don't have a path (like CONNECT), strip it to avoid printing
 	// a double-space.
-	path := r.sanitizer.Path(r.path)
-	if len(path) != 0 {
-		fmt = append(fmt, " %{path}s"...)
-		arg = append(arg, convS2E(&path))
+	if path := r.sanitizer.Path(r.path); len(path) != 0 {
+		v = append(v, events.String(path))
+		f |= formatPath
 	}
 
 	// Don't output a '?' character when the query string is empty, this is
 	// a more natural way of reading URLs.
-	query := r.sanitizer.Query(r.query)
-	if len(query) != 0 {
-		fmt = append(fmt, "?%{query}s"...)
-		arg = append(arg, convS2E(&query))
+	if query := r.sanitizer.Query(r.query); len(query) != 0 {
+		v = append(v, events.String(query))
+		f |= formatQuery
 	}
 
 	// Same than with the query string, don't output a '#' character when
 	// there is no fragment.
 	if len(r.fragment) != 0 {
-		fmt = append(fmt, "#%{fragment}s"...)
-		arg = append(arg, convS2E(&r.fragment))
+		v = append(v, events.String(r.fragment))
+		f |= formatFragment
+	}
+
+	v = append(v, events.Int(r.status), events.String(r.statusText), events.String(r.agent))
+	arg := r.argbuf[:0]
+
+	for i := range v {
+		arg = append(arg, &v[i])
+	}
+
+	if r.panic != nil {
+		// Panics are rare enough that allocating a new argument list to
+		// report them is not an issue.
+		extra := make(events.Args, 0, len(r.extraArgs)+1)
+		extra = append(extra, r.extraArgs...)
+		extra = append(extra, events.Arg{Name: "panic", Value: r.panic})
+		arg = append(arg, extra)
+	} else {
+		arg = append(arg, r.extra)
 	}
-	fmt = append(fmt, " - %{status}d %s - %q"...)
-	arg = append(arg, convI2E(&r.status), convS2E(&r.statusText), convS2E(&r.agent))
-	arg = append(arg, convA2E(&r.extraArgs))
 
 	// Adjust the call depth so we can track the caller of the handler or the
 	// transport outside of the httpevents package.
@@ -137,26 +174,51 @@ func (r *request) log(logger *events.Logger, resHeader http.Header, depth int) {
 
 	switch {
 	case is4xx(r.status) || is5xx(r.status):
-		l.Log(bytesToStringNonEmpty(fmt), arg...)
+		l.Log(formats[f], arg...)
 	default:
-		l.Debug(bytesToStringNonEmpty(fmt), arg...)
+		l.Debug(formats[f], arg...)
 	}
 
 	r.argbuf = arg
-	r.logbuf = fmt
 }
 
+const (
+	formatPath = 1 << iota
+	formatQuery
+	formatFragment
+)
+
+// formats is indexed by combinations of the formatPath, formatQuery and
+// formatFragment flags, it holds the log formats of requests.
+var formats = func() (formats [8]string) {
+	for f := range formats {
+		s := "%{local_address}s->%{remote_address}s - %{host}s - %{method}s"
+		if f&formatPath != 0 {
+			s += " %{path}s"
+		}
+		if f&formatQuery != 0 {
+			s += "?%{query}s"
+		}
+		if f&formatFragment != 0 {
+			s += "#%{fragment}s"
+		}
+		formats[f] = s + " - %{status}d %s - %q"
+	}
+	return
+}()
+
 var requestPool = sync.Pool{
 	New: func() interface{} { return newRequest() },
 }
 
 func newRequest() *request {
-	return &request{
-		fmtbuf:    make([]byte, 0, 64),
-		logbuf:    make([]byte, 0, 128),
-		argbuf:    make([]interface{}, 0, 10),
+	r := &request{
+		argbuf:    make([]interface{}, 0, 11),
+		extraArgs: make(events.Args, 4),
 		sanitizer: DefaultLogSanitizer,
 	}
+	r.extra = r.extraArgs
+	return r
 }
 
 func is4xx(status int) bool {
@@ -0,0 +1,34 @@
+package httpevents
+
+import (
+	"net/http"
+
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/events/v2/ecslogs"
+)
+
+// NewRingDumpHandler returns an HTTP handler which responds to GET requests with
+// the events recorded by ring, from the oldest to the most recent, encoded in
+// the ecs-logs format (one JSON object per line). Reading the events doesn't
+// empty the ring.
+func NewRingDumpHandler(ring *events.RingHandler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			res.Header().Set("Allow", "GET, HEAD")
+			http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/x-ndjson")
+		res.WriteHeader(http.StatusOK)
+
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		h := ecslogs.NewHandler(res)
+		for _, e := range ring.Events() {
+			h.HandleEvent(e)
+		}
+	})
+}
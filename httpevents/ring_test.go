@@ -0,0 +1,40 @@
+package httpevents
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+func TestRingDumpHandler(t *testing.T) {
+	ring := events.NewRingHandler(events.Discard, 2)
+	now := time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC)
+
+	ring.HandleEvent(&events.Event{Message: "A", Time: now, Debug: true})
+	ring.HandleEvent(&events.Event{Message: "B", Time: now, Debug: true, Args: events.Args{{Name: "id", Value: 1}}})
+
+	h := NewRingDumpHandler(ring)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+	if res.Code != http.StatusOK {
+		t.Error("bad status:", res.Code)
+	}
+
+	if s := res.Body.String(); s != `{"level":"DEBUG","time":"2017-01-01T23:42:00Z","info":{},"data":{},"message":"A"}
+{"level":"DEBUG","time":"2017-01-01T23:42:00Z","info":{},"data":{"id":1},"message":"B"}
+` {
+		t.Error(s)
+	}
+
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("POST", "/", nil))
+
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Error("bad status:", res.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package httpevents
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceParentHeader is the name of the W3C trace context header carrying
+	// the trace and parent span identifiers.
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the name of the W3C trace context header carrying
+	// vendor-specific trace information.
+	TraceStateHeader = "tracestate"
+)
+
+// TraceFlagSampled is the flag set on trace contexts when the caller may have
+// recorded the trace.
+const TraceFlagSampled byte = 0x01
+
+// TraceContext represents a W3C trace context (https://www.w3.org/TR/trace-context/).
+type TraceContext struct {
+	// TraceID is the identifier of the distributed trace.
+	TraceID [16]byte
+
+	// SpanID is the identifier of the current span.
+	SpanID [8]byte
+
+	// ParentID is the identifier of the span that the current span is a child
+	// of, it is zero for root spans.
+	ParentID [8]byte
+
+	// Flags is the set of trace flags, like TraceFlagSampled.
+	Flags byte
+
+	// State is the value of the tracestate header, propagated unmodified.
+	State string
+}
+
+// NewTraceContext generates a trace context for a new trace.
+func NewTraceContext() TraceContext {
+	tc := TraceContext{}
+	randomID(tc.TraceID[:])
+	randomID(tc.SpanID[:])
+	return tc
+}
+
+// NewChild generates a trace context for a child span of tc.
+func (tc TraceContext) NewChild() TraceContext {
+	child := tc
+	child.ParentID = tc.SpanID
+	randomID(child.SpanID[:])
+	return child
+}
+
+// IsValid returns true if tc has non-zero trace and span identifiers.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+// TraceIDString returns the hexadecimal representation of the trace identifier.
+func (tc TraceContext) TraceIDString() string {
+	return hex.EncodeToString(tc.TraceID[:])
+}
+
+// SpanIDString returns the hexadecimal representation of the span identifier.
+func (tc TraceContext) SpanIDString() string {
+	return hex.EncodeToString(tc.SpanID[:])
+}
+
+// TraceParent returns the value of the traceparent header propagating tc, the
+// current span is the parent of the spans receiving the header.
+func (tc TraceContext) TraceParent() string {
+	var b [traceParentLen]byte
+	copy(b[:], "00-")
+	hex.Encode(b[3:35], tc.TraceID[:])
+	b[35] = '-'
+	hex.Encode(b[36:52], tc.SpanID[:])
+	b[52] = '-'
+	hex.Encode(b[53:], []byte{tc.Flags})
+	return string(b[:])
+}
+
+// ParseTraceParent parses the value of a traceparent header. The SpanID field
+// of the returned trace context is set to the parent identifier found in the
+// header, which is the identifier of the caller's span.
+func ParseTraceParent(s string) (TraceContext, error) {
+	tc := TraceContext{}
+
+	// Versions greater than 00 may append fields to the header, but the
+	// first ones must remain parseable by implementations of version 00.
+	if len(s) < traceParentLen || (len(s) > traceParentLen && (s[:2] == "00" || s[traceParentLen] != '-')) {
+		return tc, errInvalidTraceParent
+	}
+
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' || s[:2] == "ff" {
+		return tc, errInvalidTraceParent
+	}
+
+	var version, flags [1]byte
+	if !decodeHex(version[:], s[:2]) ||
+		!decodeHex(tc.TraceID[:], s[3:35]) ||
+		!decodeHex(tc.SpanID[:], s[36:52]) ||
+		!decodeHex(flags[:], s[53:55]) {
+		return tc, errInvalidTraceParent
+	}
+	tc.Flags = flags[0]
+
+	if !tc.IsValid() {
+		return tc, errInvalidTraceParent
+	}
+
+	return tc, nil
+}
+
+// TraceContextFromRequest returns the trace context propagated by the headers
+// of req, the boolean is false if req had no valid traceparent header.
+func TraceContextFromRequest(req *http.Request) (TraceContext, bool) {
+	tc, err := ParseTraceParent(req.Header.Get(TraceParentHeader))
+	if err != nil {
+		return TraceContext{}, false
+	}
+	tc.State = strings.Join(req.Header.Values(TraceStateHeader), ",")
+	return tc, true
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx which carries tc.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context carried by ctx, the
+// boolean is false if ctx had none.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+const traceParentLen = 55
+
+var errInvalidTraceParent = errors.New("httpevents: invalid traceparent header")
+
+// decodeHex decodes the lower-case hexadecimal string s into b, which must be
+// large enough to hold the decoded bytes.
+func decodeHex(b []byte, s string) bool {
+	if strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(b, []byte(s))
+	return err == nil
+}
+
+// randUint64 generates the random numbers that identifiers are made of. Trace
+// identifiers only need to be unique, not unpredictable, so the cheaper
+// math/rand generator is used instead of crypto/rand. It is a variable so tests
+// can generate predictable identifiers.
+var randUint64 = rand.Uint64
+
+// randomID fills b with random bytes, its length must be a multiple of 8.
+func randomID(b []byte) {
+	for {
+		for i := 0; i < len(b); i += 8 {
+			binary.BigEndian.PutUint64(b[i:], randUint64())
+		}
+
+		for _, c := range b {
+			if c != 0 {
+				return
+			}
+		}
+	}
+}
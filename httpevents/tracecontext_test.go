@@ -0,0 +1,135 @@
+package httpevents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/segmentio/events/v2"
+)
+
+const (
+	testTraceID = "01020304050607080102030405060708"
+	testSpanID  = "0102030405060708"
+)
+
+func TestMain(m *testing.M) {
+	// Generate predictable identifiers so the tests can make assertions on
+	// the access logs.
+	randUint64 = func() uint64 { return 0x0102030405060708 }
+	os.Exit(m.Run())
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := tc.TraceIDString(); s != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Error("bad trace id:", s)
+	}
+	if s := tc.SpanIDString(); s != "00f067aa0ba902b7" {
+		t.Error("bad span id:", s)
+	}
+	if tc.Flags != TraceFlagSampled {
+		t.Error("bad flags:", tc.Flags)
+	}
+	if s := tc.TraceParent(); s != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Error("bad traceparent:", s)
+	}
+
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"); err != nil {
+		t.Error("future versions with extra fields must be accepted:", err)
+	}
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestTraceContextNewChild(t *testing.T) {
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	child := parent.NewChild()
+
+	if child.TraceID != parent.TraceID {
+		t.Error("the child must belong to the same trace")
+	}
+	if child.ParentID != parent.SpanID {
+		t.Error("the parent of the child must be the span of its parent")
+	}
+	if child.SpanID == parent.SpanID || !child.IsValid() {
+		t.Error("bad child span id:", child.SpanIDString())
+	}
+}
+
+func TestHandlerTraceContext(t *testing.T) {
+	var tc TraceContext
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(TraceStateHeader, "congo=t61rcWkgMzE")
+
+	h := NewHandlerWith(events.NewLogger(events.Discard), http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		tc, _ = TraceContextFromContext(req.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if s := tc.TraceIDString(); s != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Error("bad trace id:", s)
+	}
+	if s := tc.SpanIDString(); s != testSpanID {
+		t.Error("bad span id:", s)
+	}
+	if tc.ParentID != [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7} {
+		t.Error("bad parent id:", tc.ParentID)
+	}
+	if tc.State != "congo=t61rcWkgMzE" {
+		t.Error("bad trace state:", tc.State)
+	}
+}
+
+func TestTransportTraceContext(t *testing.T) {
+	var traceparent, tracestate string
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get(TraceParentHeader)
+		tracestate = req.Header.Get(TraceStateHeader)
+	}))
+	defer server.Close()
+
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	parent.State = "congo=t61rcWkgMzE"
+
+	ctx := ContextWithTraceContext(context.Background(), parent)
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+
+	res, err := NewTransportWith(events.NewLogger(events.Discard), http.DefaultTransport).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if traceparent != "00-4bf92f3577b34da6a3ce929d0e0e4736-"+testSpanID+"-01" {
+		t.Error("bad traceparent:", traceparent)
+	}
+	if tracestate != "congo=t61rcWkgMzE" {
+		t.Error("bad tracestate:", tracestate)
+	}
+	if len(req.Header) != 0 {
+		t.Error("the transport must not modify the request headers:", req.Header)
+	}
+}
@@ -14,6 +14,12 @@ func NewTransport(roundTripper http.RoundTripper) http.RoundTripper {
 
 // NewTransportWith wraps roundTripper and returns a new transport which logs
 // all submitted requests with logger.
+//
+// The transport propagates the trace context carried by the context of requests
+// (see TraceContextFromContext) by injecting the traceparent and tracestate
+// headers for a new child span, or starts a new trace if there were none.
+// Requests which already have a traceparent header are sent unmodified. The
+// trace_id and span_id of the requests are reported in the logs.
 func NewTransportWith(logger *events.Logger, roundTripper http.RoundTripper) http.RoundTripper {
 	return &transport{roundTripper, logger}
 }
@@ -24,8 +30,21 @@ type transport struct {
 }
 
 func (t *transport) RoundTrip(req *http.Request) (res *http.Response, err error) {
-	if res, err = t.RoundTripper.RoundTrip(req); res != nil {
+	out := req
+	tc, perr := ParseTraceParent(req.Header.Get(TraceParentHeader))
+
+	if perr != nil {
+		if parent, ok := TraceContextFromContext(req.Context()); ok {
+			tc = parent.NewChild()
+		} else {
+			tc = NewTraceContext()
+		}
+		out = withTraceContext(req, tc)
+	}
+
+	if res, err = t.RoundTripper.RoundTrip(out); res != nil {
 		r := acquireRequest(DefaultLogSanitizer, req, "*")
+		r.setTrace(tc.TraceIDString(), tc.SpanIDString())
 		r.status = res.StatusCode
 		r.statusText = http.StatusText(res.StatusCode)
 		r.log(t.logger, res.Header, 1)
@@ -33,3 +52,23 @@ func (t *transport) RoundTrip(req *http.Request) (res *http.Response, err error)
 	}
 	return
 }
+
+// withTraceContext returns a shallow copy of req with headers propagating tc,
+// round trippers must not modify the requests they receive.
+func withTraceContext(req *http.Request, tc TraceContext) *http.Request {
+	out := new(http.Request)
+	*out = *req
+	out.Header = make(http.Header, len(req.Header)+2)
+
+	for name, values := range req.Header {
+		out.Header[name] = values
+	}
+
+	out.Header.Set(TraceParentHeader, tc.TraceParent())
+
+	if len(tc.State) != 0 {
+		out.Header.Set(TraceStateHeader, tc.State)
+	}
+
+	return out
+}
@@ -53,6 +53,8 @@ func TestTransport(t *testing.T) {
 			{Name: "method", Value: "GET"},
 			{Name: "path", Value: "/"},
 			{Name: "status", Value: 200},
+			{Name: "trace_id", Value: testTraceID},
+			{Name: "span_id", Value: testSpanID},
 			{Name: "request", Value: &headerList{
 				{name: "User-Agent", value: "httpevents"},
 			}},
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level represents the severity level of events.
+type Level int
+
+const (
+	// LevelUnset is the zero-value of Level, the severity of events which have
+	// no explicit level is inferred from their other fields (see
+	// Event.Severity).
+	LevelUnset Level = iota
+
+	// LevelDebug is the level of debugging events.
+	LevelDebug
+
+	// LevelInfo is the level of informational events.
+	LevelInfo
+
+	// LevelWarn is the level of events reporting an unexpected condition
+	// which does not prevent the program from working properly.
+	LevelWarn
+
+	// LevelError is the level of events reporting errors.
+	LevelError
+)
+
+// String returns the upper-case name of the level, or an empty string if it is
+// unset.
+func (l Level) String() string {
+	switch l {
+	case LevelUnset:
+		return ""
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// ParseLevel returns the level matching the given name, the comparison is
+// case-insensitive and "warning" is accepted as an alias of "warn".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelUnset, fmt.Errorf("events: invalid level %q", s)
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"io"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		t.Run(level.String(), func(t *testing.T) {
+			l, err := ParseLevel(level.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != level {
+				t.Error("bad level:", l)
+			}
+		})
+	}
+
+	if l, err := ParseLevel("warning"); l != LevelWarn || err != nil {
+		t.Error("bad level:", l, err)
+	}
+
+	if _, err := ParseLevel("fatal"); err == nil {
+		t.Error("expected an error parsing an unknown level")
+	}
+}
+
+func TestEventSeverity(t *testing.T) {
+	tests := []struct {
+		event Event
+		level Level
+	}{
+		{
+			event: Event{},
+			level: LevelInfo,
+		},
+		{
+			event: Event{Debug: true},
+			level: LevelDebug,
+		},
+		{
+			event: Event{Debug: true, Args: Args{{"error", io.EOF}}},
+			level: LevelError,
+		},
+		{
+			event: Event{Args: Args{{"db", Group{{"query", Group{{"error", io.EOF}}}}}}},
+			level: LevelError,
+		},
+		{
+			event: Event{Args: Args{{"error", Any(io.EOF)}}},
+			level: LevelError,
+		},
+		{
+			event: Event{Args: Args{{"error", &Value{any: io.EOF}}}},
+			level: LevelError,
+		},
+		{
+			event: Event{Args: Args{{"error", (*Value)(nil)}}},
+			level: LevelInfo,
+		},
+		{
+			event: Event{Args: Args{{"error", Lazy(func() interface{} { return io.EOF })}}},
+			level: LevelInfo,
+		},
+		{
+			event: Event{Args: Args{{"error", io.EOF}}, Level: LevelWarn},
+			level: LevelWarn,
+		},
+		{
+			event: Event{Debug: true, Level: LevelInfo},
+			level: LevelInfo,
+		},
+	}
+
+	for _, test := range tests {
+		if level := test.event.Severity(); level != test.level {
+			t.Errorf("%#v: bad severity: %s != %s", test.event, level, test.level)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/segmentio/events/v2"
 	"github.com/segmentio/events/v2/ecslogs"
@@ -49,6 +51,10 @@ func NewHandler(w io.Writer) events.Handler {
 	return ecslogs.NewHandler(w)
 }
 
+// FlushTimeout is the maximum amount of time that the Fatal functions wait for
+// event handlers to be flushed before exiting the program.
+var FlushTimeout = 5 * time.Second
+
 // Cache of the output set for the default logger, we need this because the
 // standard log package doesn't expose any API to retrieve it.
 var defaultWriter *Writer = NewWriter(log.Prefix(), log.Flags(), nil)
@@ -163,21 +169,27 @@ func Println(v ...interface{}) {
 }
 
 // Fatal is equivalent to Print() followed by a call to os.Exit(1).
+//
+// Event handlers are flushed before the program exits.
 func Fatal(v ...interface{}) {
 	log.Output(2, fmt.Sprint(v...))
-	os.Exit(1)
+	exit(1)
 }
 
 // Fatalf is equivalent to Printf() followed by a call to os.Exit(1).
+//
+// Event handlers are flushed before the program exits.
 func Fatalf(format string, v ...interface{}) {
 	log.Output(2, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	exit(1)
 }
 
 // Fatalln is equivalent to Println() followed by a call to os.Exit(1).
+//
+// Event handlers are flushed before the program exits.
 func Fatalln(v ...interface{}) {
 	log.Output(2, fmt.Sprintln(v...))
-	os.Exit(1)
+	exit(1)
 }
 
 // Panic is equivalent to Print() followed by a call to panic().
@@ -213,3 +225,21 @@ func Output(calldepth int, s string) error {
 }
 
 // =============================================================================
+
+// exit flushes the handler of the default writer and the default handlers of
+// the events package, then terminates the program with code.
+func exit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), FlushTimeout)
+
+	defaultWriter.mutex.Lock()
+	handler := defaultWriter.handler
+	defaultWriter.mutex.Unlock()
+
+	if handler != nil {
+		events.FlushHandler(ctx, handler)
+	}
+
+	events.Flush(ctx)
+	cancel()
+	os.Exit(code)
+}
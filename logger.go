@@ -1,11 +1,13 @@
 package events
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,7 +20,7 @@ var DefaultLogger = NewLogger(nil)
 // Deprecated: Use the log/slog standard library package (or Segment internal,
 // github.com/segmentio/log).
 func Log(format string, args ...interface{}) {
-	DefaultLogger.log(1, false, format, args...)
+	DefaultLogger.log(nil, 1, false, LevelUnset, format, args...)
 }
 
 // Debug emits a debug event to the default logger.
@@ -26,7 +28,17 @@ func Log(format string, args ...interface{}) {
 // Deprecated: Use the log/slog standard library package (or Segment internal,
 // github.com/segmentio/log).
 func Debug(format string, args ...interface{}) {
-	DefaultLogger.debug(1, format, args...)
+	DefaultLogger.debug(nil, 1, format, args...)
+}
+
+// Warn emits a warning event to the default logger.
+func Warn(format string, args ...interface{}) {
+	DefaultLogger.log(nil, 1, false, LevelWarn, format, args...)
+}
+
+// Error emits an error event to the default logger.
+func Error(format string, args ...interface{}) {
+	DefaultLogger.log(nil, 1, false, LevelError, format, args...)
 }
 
 // A Logger is a wrapper around an event handler which exposes a Log method for
@@ -77,9 +89,44 @@ type Logger struct {
 	EnableSource bool
 
 	// EnableDebug controls whether calls to Debug produces events.
+	//
+	// The field must not be modified while the logger is in use, programs
+	// that need to toggle debug events at runtime should use SetDebug.
 	EnableDebug bool
+
+	// DebugFilter selects the call sites allowed to produce debug events. Call
+	// sites matched by one of the filter's rules produce debug events if the
+	// rule enables them, regardless of the value of EnableDebug, which still
+	// applies to the call sites that no rules matched.
+	//
+	// Loggers created by NewLogger use DefaultDebugFilter.
+	DebugFilter *DebugFilter
+
+	// EnableStackOnError controls whether the logger captures the stack of its
+	// caller in the events which have at least one error argument (see
+	// Event.Stack). Handlers report the stack along with errors which don't
+	// carry one.
+	EnableStackOnError bool
+
+	// TimeThreshold is the minimum duration of the operations measured with
+	// Time that produce events, faster operations which didn't fail are not
+	// logged.
+	TimeThreshold time.Duration
+
+	// DedupArgs controls whether the arguments added by With replace the
+	// values of arguments with the same names instead of being appended.
+	DedupArgs bool
+
+	groups     []loggerGroup // groups opened by WithGroup
+	debugState uint32        // debugUnset, debugEnabled or debugDisabled (see SetDebug)
 }
 
+const (
+	debugUnset uint32 = iota
+	debugEnabled
+	debugDisabled
+)
+
 // NewLogger allocates and returns a new logger which sends events to handler.
 //
 // Deprecated: Use the log/slog standard library package (or Segment internal,
@@ -89,15 +136,55 @@ func NewLogger(handler Handler) *Logger {
 		Handler:      handler,
 		EnableSource: true,
 		EnableDebug:  true,
+		DebugFilter:  DefaultDebugFilter,
 	}
 }
 
 // Log formats an event and sends it to the logger's handler.
 func (l *Logger) Log(format string, args ...interface{}) {
-	l.log(1, false, format, args...)
+	l.log(nil, 1, false, LevelUnset, format, args...)
+}
+
+// Warn is like Log but produces events at the LevelWarn level.
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.log(nil, 1, false, LevelWarn, format, args...)
+}
+
+// Error is like Log but produces events at the LevelError level.
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.log(nil, 1, false, LevelError, format, args...)
 }
 
-func (l *Logger) log(depth int, debug bool, format string, args ...interface{}) {
+// log produces an event from the given format and arguments, ctx may be nil when
+// the event isn't logged in the scope of a context.
+func (l *Logger) log(ctx context.Context, depth int, debug bool, level Level, format string, args ...interface{}) {
+	var pc uintptr
+
+	if l.EnableSource {
+		pc = l.caller(depth + 1)
+	}
+
+	l.logPC(ctx, depth+1, pc, debug, level, format, args...)
+}
+
+// caller returns the program counter address of the caller of the logger's
+// methods, depth is the number of stack frames between the caller of caller
+// and the logger's method.
+func (l *Logger) caller(depth int) uintptr {
+	var pc [1]uintptr
+	runtime.Callers(l.CallDepth+depth+2, pc[:])
+	return pc[0]
+}
+
+// stack captures the stack of the caller of the logger's methods into buf, depth
+// has the same meaning as in caller.
+func (l *Logger) stack(depth int, buf []uintptr) []uintptr {
+	return buf[:runtime.Callers(l.CallDepth+depth+2, buf)]
+}
+
+// logPC produces an event reporting pc as its source, depth is the number of
+// stack frames between the caller of logPC and the logger's method.
+func (l *Logger) logPC(ctx context.Context, depth int, pc uintptr, debug bool, level Level, format string, args ...interface{}) {
 	h := l.Handler
 	s := logPool.Get().(*logState)
 	var a Args
@@ -106,16 +193,11 @@ func (l *Logger) log(depth int, debug bool, format string, args ...interface{})
 		h = DefaultHandler
 	}
 
-	if l.EnableSource {
-		var pc [1]uintptr
-		runtime.Callers(l.CallDepth+depth+2, pc[:])
-
-		if pc[0] != 0 {
-			file, line := SourceForPC(pc[0])
-			s.src = append(s.src, file...)
-			s.src = append(s.src, ':')
-			s.src = strconv.AppendUint(s.src, uint64(line), 10)
-		}
+	if pc != 0 {
+		file, line := SourceForPC(pc)
+		s.src = append(s.src, file...)
+		s.src = append(s.src, ':')
+		s.src = strconv.AppendUint(s.src, uint64(line), 10)
 	}
 
 	if n := len(args); n != 0 {
@@ -125,26 +207,44 @@ func (l *Logger) log(depth int, debug bool, format string, args ...interface{})
 	}
 
 	s.e.Args = append(s.e.Args, l.Args...)
+	s.e.Args = AppendArgsFromContext(s.e.Args, ctx)
+	base := len(s.e.Args)
 	s.fmt, s.e.Args = appendFormat(s.fmt, s.e.Args, format, args)
 	s.e.Args = append(s.e.Args, a...)
 
+	if len(l.groups) != 0 {
+		// The arguments of the log call are moved to the innermost group,
+		// the ones past the end of the list must be cleared as well.
+		n := len(s.e.Args)
+		s.e.Args = appendGroups(s.e.Args[:base], l.groups, s.e.Args[base:])
+		if m := len(s.e.Args); m < n {
+			clearArgs(s.e.Args[m:n])
+		}
+	}
+
+	if l.EnableStackOnError && hasError(s.e.Args) {
+		s.e.Stack = l.stack(depth+1, s.stk[:])
+	}
+
 	fmt.Fprintf(s, bytesToString(s.fmt), args...)
 
 	s.e.Message = bytesToString(s.msg)
 	s.e.Source = bytesToString(s.src)
+	s.e.Format = format
 	s.e.Debug = debug
+	s.e.Level = level
 	s.e.Time = time.Now()
 
 	h.HandleEvent(&s.e)
 
 	// don't hold pointers to let the garbage collector free the objects
-	for i := range s.e.Args {
-		s.e.Args[i] = Arg{}
-	}
+	clearArgs(s.e.Args)
 
 	s.e.Message = ""
 	s.e.Source = ""
+	s.e.Format = ""
 	s.e.Args = s.e.Args[:0]
+	s.e.Stack = nil
 
 	s.fmt = s.fmt[:0]
 	s.msg = s.msg[:0]
@@ -155,33 +255,130 @@ func (l *Logger) log(depth int, debug bool, format string, args ...interface{})
 }
 
 // Debug is like Log but only produces events if the logger has debugging
-// enabled.
+// enabled, either globally or for the caller's package (see DebugFilter).
 func (l *Logger) Debug(format string, args ...interface{}) {
-	l.debug(1, format, args...)
+	l.debug(nil, 1, format, args...)
 }
 
-func (l *Logger) debug(depth int, format string, args ...interface{}) {
-	if l.EnableDebug {
-		l.log(depth+1, true, format, args...)
+func (l *Logger) debug(ctx context.Context, depth int, format string, args ...interface{}) {
+	if f := l.DebugFilter; f != nil && !f.empty() {
+		pc := l.caller(depth + 1)
+
+		switch f.match(pc) {
+		case debugOn:
+		case debugOff:
+			return
+		default:
+			if !l.DebugEnabled() {
+				return
+			}
+		}
+
+		if !l.EnableSource {
+			pc = 0
+		}
+
+		l.logPC(ctx, depth+1, pc, true, LevelUnset, format, args...)
+		return
+	}
+
+	if l.DebugEnabled() {
+		l.log(ctx, depth+1, true, LevelUnset, format, args...)
+	}
+}
+
+// SetDebug enables or disables debug events, overriding the value of
+// EnableDebug. Unlike assigning EnableDebug, SetDebug is safe to call while
+// other goroutines are using the logger.
+func (l *Logger) SetDebug(enable bool) {
+	if enable {
+		atomic.StoreUint32(&l.debugState, debugEnabled)
+	} else {
+		atomic.StoreUint32(&l.debugState, debugDisabled)
+	}
+}
+
+// DebugEnabled returns true if calls to Debug produce events, as configured by
+// SetDebug, or EnableDebug if SetDebug was never called.
+func (l *Logger) DebugEnabled() bool {
+	switch atomic.LoadUint32(&l.debugState) {
+	case debugEnabled:
+		return true
+	case debugDisabled:
+		return false
+	default:
+		return l.EnableDebug
 	}
 }
 
 // With returns a new Logger which is a copy of l augmented with args.
+//
+// If l has groups opened by WithGroup, args are added to the innermost group.
+// If l has DedupArgs set, args replace the values of the arguments with the
+// same names that l already had instead of being appended.
 func (l *Logger) With(args Args) *Logger {
-	var newArgs Args
-	newLen := len(l.Args) + len(args)
-
-	if newLen != 0 {
-		newArgs = make(Args, 0, newLen)
-		newArgs = append(newArgs, l.Args...)
-		newArgs = append(newArgs, args...)
+	c := l.clone()
+
+	if n := len(l.groups); n == 0 {
+		c.Args = l.appendArgs(l.Args, args)
+	} else {
+		c.groups = make([]loggerGroup, n)
+		copy(c.groups, l.groups)
+		c.groups[n-1].args = l.appendArgs(l.groups[n-1].args, args)
 	}
 
+	return c
+}
+
+// WithGroup returns a new Logger which is a copy of l where the arguments of
+// the events, as well as the arguments added by subsequent calls to With, are
+// nested in a group named name (see Group).
+//
+// The arguments carried by contexts passed to the context-aware methods of the
+// logger are not added to the group.
+func (l *Logger) WithGroup(name string) *Logger {
+	c := l.clone()
+	c.groups = make([]loggerGroup, 0, len(l.groups)+1)
+	c.groups = append(c.groups, l.groups...)
+	c.groups = append(c.groups, loggerGroup{name: name})
+	return c
+}
+
+func (l *Logger) clone() *Logger {
 	return &Logger{
-		Args:         newArgs,
+		Args:         l.Args,
 		Handler:      l.Handler,
 		EnableSource: l.EnableSource,
-		EnableDebug:  l.EnableDebug,
+		EnableDebug:  l.DebugEnabled(),
+		DebugFilter:  l.DebugFilter,
+		DedupArgs:    l.DedupArgs,
+		groups:       l.groups,
+
+		EnableStackOnError: l.EnableStackOnError,
+		TimeThreshold:      l.TimeThreshold,
+	}
+}
+
+// appendArgs returns a new argument list made of base and args.
+func (l *Logger) appendArgs(base Args, args Args) Args {
+	n := len(base) + len(args)
+	if n == 0 {
+		return nil
+	}
+
+	a := make(Args, 0, n)
+	a = append(a, base...)
+
+	if l.DedupArgs {
+		return setArgs(a, args)
+	}
+
+	return append(a, args...)
+}
+
+func clearArgs(args Args) {
+	for i := range args {
+		args[i] = Arg{}
 	}
 }
 
@@ -191,8 +388,13 @@ type logState struct {
 	fmt []byte
 	msg []byte
 	src []byte
+	stk [maxStackDepth]uintptr
 }
 
+// maxStackDepth is the maximum number of frames captured in the stacks of
+// events.
+const maxStackDepth = 64
+
 func (s *logState) Write(b []byte) (n int, err error) {
 	s.msg = append(s.msg, b...)
 	n = len(b)
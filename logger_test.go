@@ -1,7 +1,9 @@
 package events
 
 import (
+	"io"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -147,6 +149,22 @@ func TestLogger(t *testing.T) {
 					checkEvents(t, events, nil)
 				})
 			})
+			t.Run("Warn", func(t *testing.T) {
+				events = events[:0]
+				logger.Warn(test.format, test.args...)
+
+				testEvent := test.event
+				testEvent.Level = LevelWarn
+				checkEvents(t, events, []*Event{&testEvent})
+			})
+			t.Run("Error", func(t *testing.T) {
+				events = events[:0]
+				logger.Error(test.format, test.args...)
+
+				testEvent := test.event
+				testEvent.Level = LevelError
+				checkEvents(t, events, []*Event{&testEvent})
+			})
 		})
 	}
 
@@ -171,6 +189,108 @@ func TestLogger(t *testing.T) {
 			},
 		})
 	})
+
+	t.Run("EnableStackOnError", func(t *testing.T) {
+		logger := logger
+		logger.EnableStackOnError = true
+		logger.EnableDebug = true
+
+		events = events[:0]
+		logger.Log("hello")
+		logger.Log("failed: %{error}v", io.EOF)
+		logger.Debug("failed: %{error}v", io.EOF)
+		logger.With(nil).Log("failed", Args{{"error", io.EOF}})
+
+		if len(events) != 4 {
+			t.Fatal("bad number of events:", len(events))
+		}
+
+		if events[0].Stack != nil {
+			t.Error("no stacks must be captured for events without errors")
+		}
+
+		for _, e := range events[1:] {
+			if len(e.Stack) == 0 {
+				t.Error("no stack was captured:", e.Message)
+				continue
+			}
+
+			if file, line := SourceForPC(e.Stack[0]); file+":"+strconv.Itoa(line) != e.Source {
+				t.Errorf("the stack must start at the caller of the logger: %s:%d != %s", file, line, e.Source)
+			}
+		}
+	})
+
+	t.Run("WithGroup", func(t *testing.T) {
+		child1 := logger.With(Args{{"id", 1}}).WithGroup("db").With(Args{{"id", 2}})
+		child2 := child1.WithGroup("query")
+		child3 := child1.WithGroup("empty")
+
+		events = events[:0]
+		child1.Log("child1 %{table}s", "users")
+		child2.Log("child2", Args{{"id", 3}})
+		child3.Log("child3")
+
+		checkEvents(t, events, []*Event{
+			{
+				Message: "child1 users",
+				Args:    Args{{"id", 1}, {"db", Group{{"id", 2}, {"table", "users"}}}},
+			},
+			{
+				Message: "child2",
+				Args:    Args{{"id", 1}, {"db", Group{{"id", 2}, {"query", Group{{"id", 3}}}}}},
+			},
+			{
+				Message: "child3",
+				Args:    Args{{"id", 1}, {"db", Group{{"id", 2}}}},
+			},
+		})
+	})
+
+	t.Run("DedupArgs", func(t *testing.T) {
+		logger := logger
+		logger.DedupArgs = true
+
+		child := logger.With(Args{{"id", 1}, {"name", "Luke"}}).With(Args{{"id", 2}})
+
+		events = events[:0]
+		child.Log("hello")
+
+		checkEvents(t, events, []*Event{
+			{
+				Message: "hello",
+				Args:    Args{{"id", 2}, {"name", "Luke"}},
+			},
+		})
+
+		if g := child.WithGroup("db").With(Args{{"id", 3}}).With(Args{{"id", 4}}).groups; !reflect.DeepEqual(g, []loggerGroup{{"db", Args{{"id", 4}}}}) {
+			t.Errorf("arguments of groups must be deduplicated: %#v", g)
+		}
+	})
+
+	t.Run("SetDebug", func(t *testing.T) {
+		logger := &Logger{Handler: logger.Handler}
+		logger.EnableDebug = true
+
+		logger.SetDebug(false)
+		events = events[:0]
+		logger.Debug("hello")
+
+		if len(events) != 0 {
+			t.Error("SetDebug(false) must override EnableDebug")
+		}
+
+		if logger.With(Args{{"id", 1}}).DebugEnabled() {
+			t.Error("loggers returned by With must inherit the state set by SetDebug")
+		}
+
+		logger.SetDebug(true)
+		logger.Debug("hello")
+
+		if len(events) != 1 {
+			t.Error("SetDebug(true) must enable debug events")
+		}
+	})
 }
 
 func BenchmarkLogger(b *testing.B) {
@@ -206,6 +326,8 @@ func checkEvents(t *testing.T, e1 []*Event, e2 []*Event) {
 		v2 := *e2[i]
 		v1.Source = ""
 		v2.Source = ""
+		v1.Format = ""
+		v2.Format = ""
 		v1.Time = time.Time{}
 		v2.Time = time.Time{}
 		if !reflect.DeepEqual(v1, v2) {
@@ -0,0 +1,71 @@
+package events
+
+import "time"
+
+// Measure starts measuring the duration of an operation with the default
+// logger, see Logger.Time.
+func Measure(format string, args ...interface{}) func(error) {
+	return DefaultLogger.time(1, format, args...)
+}
+
+// Time starts measuring the duration of an operation, and returns a function
+// which stops the measure when called. The stop function produces an event
+// formatted from format and args (like Log), with a "duration" argument set to
+// the time elapsed since the call to Time, and an "error" argument set to the
+// error passed to the stop function if it wasn't nil. The source of the event
+// is the caller of Time.
+//
+// Operations faster than the logger's TimeThreshold don't produce events,
+// unless they failed.
+//
+// Here's an example of measuring the duration of a function:
+//
+//	func (s *store) query(table string) (err error) {
+//		stop := s.logger.Time("querying %{table}s", table)
+//		defer func() { stop(err) }()
+//		...
+//	}
+func (l *Logger) Time(format string, args ...interface{}) func(error) {
+	return l.time(1, format, args...)
+}
+
+func (l *Logger) time(depth int, format string, args ...interface{}) func(error) {
+	var pc uintptr
+
+	if l.EnableSource {
+		pc = l.caller(depth + 1)
+	}
+
+	start := time.Now()
+
+	return func(err error) {
+		d := time.Since(start)
+
+		if d < l.TimeThreshold && err == nil {
+			return
+		}
+
+		var a Args
+		n := len(args)
+
+		if n != 0 {
+			if s, ok := args[n-1].(Args); ok {
+				a, n = s, n-1
+			}
+		}
+
+		extra := make(Args, 0, len(a)+2)
+		extra = append(extra, a...)
+		extra = append(extra, Arg{"duration", d})
+
+		if err != nil {
+			extra = append(extra, Arg{"error", err})
+		}
+
+		v := make([]interface{}, 0, n+1)
+		v = append(v, args[:n]...)
+		v = append(v, extra)
+
+		l.logPC(nil, 1, pc, false, LevelUnset, format, v...)
+	}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoggerTime(t *testing.T) {
+	var evlist []*Event
+	logger := NewLogger(HandlerFunc(func(e *Event) { evlist = append(evlist, e.Clone()) }))
+
+	_, file, line, _ := runtime.Caller(0)
+	stop := logger.Time("querying %{table}s", "users", Args{{"id", 42}})
+	time.Sleep(time.Millisecond)
+	stop(io.EOF)
+
+	if len(evlist) != 1 {
+		t.Fatal("bad number of events:", len(evlist))
+	}
+
+	e := evlist[0]
+
+	if e.Message != "querying users" {
+		t.Error("bad message:", e.Message)
+	}
+
+	if src, _ := SourceForPC(pcOf()); e.Source != src+":"+strconv.Itoa(line+1) {
+		t.Errorf("the source must be the caller of Time: %s (%s:%d)", e.Source, file, line+1)
+	}
+
+	d, _ := e.Args.Get("duration")
+	if d, ok := d.(time.Duration); !ok || d < time.Millisecond {
+		t.Error("bad duration:", d)
+	}
+
+	if names := argNames(e.Args); !reflect.DeepEqual(names, []string{"table", "id", "duration", "error"}) {
+		t.Error("bad arguments:", names)
+	}
+
+	if e.Severity() != LevelError {
+		t.Error("operations which failed must produce errors:", e.Severity())
+	}
+}
+
+func TestLoggerTimeThreshold(t *testing.T) {
+	var evlist []*Event
+	logger := NewLogger(HandlerFunc(func(e *Event) { evlist = append(evlist, e.Clone()) }))
+	logger.TimeThreshold = time.Hour
+
+	logger.Time("fast")(nil)
+	logger.With(nil).Time("failed")(io.EOF)
+
+	if len(evlist) != 1 || evlist[0].Message != "failed" {
+		t.Error("only the operations which failed must be logged:", evlist)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	var evlist []*Event
+	handler := DefaultLogger.Handler
+	defer func() { DefaultLogger.Handler = handler }()
+	DefaultLogger.Handler = HandlerFunc(func(e *Event) { evlist = append(evlist, e.Clone()) })
+
+	Measure("done")(nil)
+
+	if len(evlist) != 1 || evlist[0].Message != "done" {
+		t.Error("bad events:", evlist)
+	}
+}
+
+// pcOf returns a program counter in the function calling it, used to get the
+// file name as reported by SourceForPC.
+func pcOf() uintptr {
+	pc, _, _, _ := runtime.Caller(1)
+	return pc
+}
+
+func argNames(args Args) []string {
+	names := make([]string, len(args))
+	for i, a := range args {
+		names[i] = a.Name
+	}
+	return names
+}
@@ -47,6 +47,7 @@ func TestDialFunc(t *testing.T) {
 
 	if !reflect.DeepEqual(e1, events.Event{
 		Message: "127.0.0.1:56789->127.0.0.1:80 - opening client tcp connection",
+		Format:  "%{local_address}s->%{remote_address}s - %{event}s %{type}s %{protocol}s connection",
 		Args: events.Args{
 			{Name: "local_address", Value: "127.0.0.1:56789"},
 			{Name: "remote_address", Value: "127.0.0.1:80"},
@@ -60,6 +61,7 @@ func TestDialFunc(t *testing.T) {
 
 	if !reflect.DeepEqual(e2, events.Event{
 		Message: "127.0.0.1:56789->127.0.0.1:80 - closing client tcp connection",
+		Format:  "%{local_address}s->%{remote_address}s - %{event}s %{type}s %{protocol}s connection",
 		Args: events.Args{
 			{Name: "local_address", Value: "127.0.0.1:56789"},
 			{Name: "remote_address", Value: "127.0.0.1:80"},
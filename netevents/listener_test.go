@@ -44,6 +44,7 @@ func TestListener(t *testing.T) {
 
 	for _, e := range evList {
 		e.Source = ""
+		e.Format = ""
 		e.Time = time.Time{}
 	}
 
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PanicError is the error reported in the events produced for recovered panics.
+//
+// PanicError implements the StackTrace method of errors created by the
+// github.com/pkg/errors package, and the %+v formatting verb prints the stack
+// trace after the error message, so handlers report the stack of the goroutine
+// which panicked.
+type PanicError struct {
+	// Value is the value that was passed to panic.
+	Value interface{}
+
+	// Stack holds the program counters of the stack frames of the goroutine
+	// which panicked, starting at the function which called panic.
+	Stack []uintptr
+}
+
+// NewPanicError returns a PanicError for value, capturing the stack of the
+// goroutine which panicked. It must be called from the deferred function which
+// recovered the panic, so the stack of the goroutine is still available.
+func NewPanicError(value interface{}) *PanicError {
+	var pcs [maxStackDepth]uintptr
+	stack := pcs[:runtime.Callers(2, pcs[:])]
+
+	// Drop the frames of the deferred functions and of the runtime, so the
+	// stack starts at the function which panicked.
+	for i, pc := range stack {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			stack = stack[i+1:]
+			break
+		}
+	}
+
+	for len(stack) > 1 {
+		fn := runtime.FuncForPC(stack[0] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+		stack = stack[1:]
+	}
+
+	return &PanicError{
+		Value: value,
+		Stack: append([]uintptr(nil), stack...),
+	}
+}
+
+// Error satisfies the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it was an error, or nil.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// StackTrace returns the stack of the goroutine which panicked.
+func (e *PanicError) StackTrace() errors.StackTrace {
+	st := make(errors.StackTrace, len(e.Stack))
+	for i, pc := range e.Stack {
+		st[i] = errors.Frame(pc)
+	}
+	return st
+}
+
+// Format satisfies the fmt.Formatter interface.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		e.StackTrace().Format(s, verb)
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// DefaultRecoverFlushTimeout is the time limit for flushing handlers after a
+// panic was logged, used when RecoverOptions.FlushTimeout is zero.
+const DefaultRecoverFlushTimeout = 5 * time.Second
+
+// RecoverOptions configures the behavior of Recover.
+type RecoverOptions struct {
+	// Repanic controls whether the panic is propagated after being logged.
+	Repanic bool
+
+	// FlushTimeout is the time limit for flushing the handler of the logger
+	// after the panic was logged. When zero, DefaultRecoverFlushTimeout is
+	// used.
+	FlushTimeout time.Duration
+}
+
+// Recover recovers panics and logs them with logger (or DefaultLogger if it is
+// nil), it must be called directly by a defer statement:
+//
+//	defer events.Recover(logger, events.RecoverOptions{Repanic: true})
+//
+// Panics are reported in error events with a "panic" argument holding a
+// *PanicError, which carries the panic value and the stack of the goroutine.
+// The source of the events is the location of the panic. The handler of the
+// logger is flushed before Recover returns, or before the panic propagates if
+// Repanic was set.
+func Recover(logger *Logger, opts RecoverOptions) {
+	if v := recover(); v != nil {
+		logPanic(logger, NewPanicError(v), opts)
+
+		if opts.Repanic {
+			panic(v)
+		}
+	}
+}
+
+// Go starts a goroutine running fn, the panics of fn are recovered and logged
+// with logger (see Recover), and the goroutine exits without crashing the
+// program.
+func Go(logger *Logger, fn func()) {
+	go func() {
+		defer Recover(logger, RecoverOptions{})
+		fn()
+	}()
+}
+
+func logPanic(logger *Logger, err *PanicError, opts RecoverOptions) {
+	var pc uintptr
+
+	if logger == nil {
+		logger = DefaultLogger
+	}
+
+	if logger.EnableSource && len(err.Stack) != 0 {
+		pc = err.Stack[0]
+	}
+
+	logger.logPC(nil, 1, pc, false, LevelError, "%{panic}v", err)
+
+	timeout := opts.FlushTimeout
+	if timeout == 0 {
+		timeout = DefaultRecoverFlushTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	flushLogger(ctx, logger)
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type recordHandler struct {
+	events  []*Event
+	flushed int
+}
+
+func (h *recordHandler) HandleEvent(e *Event) { h.events = append(h.events, e.Clone()) }
+
+func (h *recordHandler) Flush(ctx context.Context) error {
+	h.flushed++
+	return nil
+}
+
+func panicky(v interface{}) {
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	h := &recordHandler{}
+	logger := NewLogger(h)
+
+	func() {
+		defer Recover(logger, RecoverOptions{})
+		panicky("bye bye!")
+	}()
+
+	if len(h.events) != 1 {
+		t.Fatal("bad number of events:", len(h.events))
+	}
+
+	e := h.events[0]
+
+	if e.Message != "panic: bye bye!" || e.Severity() != LevelError {
+		t.Errorf("bad event: %q (%s)", e.Message, e.Severity())
+	}
+
+	v, _ := e.Args.Get("panic")
+	p, ok := v.(*PanicError)
+	if !ok {
+		t.Fatalf("bad panic argument: %#v", v)
+	}
+
+	if p.Value != "bye bye!" {
+		t.Error("bad panic value:", p.Value)
+	}
+
+	if fn := runtime.FuncForPC(p.Stack[0] - 1).Name(); !strings.HasSuffix(fn, ".panicky") {
+		t.Error("the stack must start at the function which panicked:", fn)
+	}
+
+	if file, line := SourceForPC(p.Stack[0]); e.Source != file+":"+strconv.Itoa(line) {
+		t.Error("the source of the event must be the location of the panic:", e.Source)
+	}
+
+	if h.flushed != 1 {
+		t.Error("the handler must be flushed after the panic was logged")
+	}
+
+	if s := fmt.Sprintf("%+v", p); !strings.HasPrefix(s, "panic: bye bye!\n") || !strings.Contains(s, ".panicky\n") {
+		t.Error("the stack must be printed with the + flag:", s)
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	h := &recordHandler{}
+
+	defer func() {
+		if v := recover(); v != io.EOF {
+			t.Error("the panic must be propagated:", v)
+		}
+
+		if len(h.events) != 1 {
+			t.Error("the panic must be logged before being propagated")
+		} else if v, _ := h.events[0].Args.Get("panic"); !errors.Is(v.(error), io.EOF) {
+			t.Error("errors must be unwrapped from panic errors:", v)
+		}
+	}()
+
+	defer Recover(NewLogger(h), RecoverOptions{Repanic: true})
+	panicky(io.EOF)
+}
+
+func TestGo(t *testing.T) {
+	done := make(chan *Event)
+
+	Go(NewLogger(HandlerFunc(func(e *Event) { done <- e.Clone() })), func() {
+		panicky("bye bye!")
+	})
+
+	if e := <-done; e.Message != "panic: bye bye!" {
+		t.Error("bad event:", e.Message)
+	}
+}
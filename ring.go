@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// DefaultRingSize is the number of events recorded by a RingHandler when none
+// is specified.
+const DefaultRingSize = 1000
+
+// RingHandler is an event handler which acts as a flight recorder for debug
+// events: it records the last events it received in a fixed-size ring, and
+// passes the debug events recorded in the ring to the handler that it wraps
+// when it receives an event matching its trigger, giving context to errors
+// without emitting every debug event.
+//
+// Events which are not debug events are passed to the handler when they are
+// received. When an event matching the trigger is received, the debug events
+// that preceded it are passed to the handler first, then the ring is emptied.
+// The loggers sending events to a ring handler must have debugging enabled for
+// debug events to be recorded.
+//
+// The ring is made of preallocated events which the received events are
+// copied to, so a handler doesn't retain any of the values that it receives.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type RingHandler struct {
+	// Trigger is called with each event received by the handler, the debug
+	// events recorded in the ring are passed to the handler when it returns
+	// true. When nil, events at the LevelError severity trigger the handler
+	// (see Event.Severity). It must be set before the handler is used.
+	Trigger func(*Event) bool
+
+	handler Handler
+	mutex   sync.Mutex
+	ring    []Event
+	next    int // index of the slot receiving the next event
+	count   int // number of events recorded in the ring
+}
+
+// NewRingHandler creates a new ring handler which records the last size events
+// it receives and passes events to handler. If size is zero or negative,
+// DefaultRingSize is used.
+func NewRingHandler(handler Handler, size int) *RingHandler {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+	return &RingHandler{
+		handler: handler,
+		ring:    make([]Event, size),
+	}
+}
+
+// HandleEvent satisfies the Handler interface.
+func (h *RingHandler) HandleEvent(e *Event) {
+	var dump []*Event
+	triggered := h.trigger(e)
+
+	h.mutex.Lock()
+	h.record(e)
+	if triggered {
+		dump = h.drain()
+	}
+	h.mutex.Unlock()
+
+	for _, d := range dump {
+		h.handler.HandleEvent(d)
+	}
+
+	if !e.Debug {
+		h.handler.HandleEvent(e)
+	}
+}
+
+// Dump passes the debug events recorded by the handler to the handler it wraps,
+// from the oldest to the most recent, and empties the ring.
+func (h *RingHandler) Dump() {
+	h.mutex.Lock()
+	dump := h.drain()
+	h.mutex.Unlock()
+
+	for _, d := range dump {
+		h.handler.HandleEvent(d)
+	}
+}
+
+// Events returns copies of the events recorded by the handler, from the oldest
+// to the most recent, without emptying the ring.
+func (h *RingHandler) Events() []*Event {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.events()
+}
+
+// Flush satisfies the Flusher interface, it flushes the handler that h wraps.
+// The recorded events are not passed to the handler, see Dump.
+func (h *RingHandler) Flush(ctx context.Context) error {
+	return FlushHandler(ctx, h.handler)
+}
+
+// Close satisfies the Closer interface, it closes the handler that h wraps.
+func (h *RingHandler) Close(ctx context.Context) error {
+	return CloseHandler(ctx, h.handler)
+}
+
+func (h *RingHandler) trigger(e *Event) bool {
+	if h.Trigger != nil {
+		return h.Trigger(e)
+	}
+	return e.Severity() == LevelError
+}
+
+// record copies e to the next slot of the ring, reusing the memory of the
+// argument list of the event previously held in the slot. It must be called
+// with the mutex held.
+func (h *RingHandler) record(e *Event) {
+	slot := &h.ring[h.next]
+	args := slot.Args[:0]
+
+	for _, a := range e.Args {
+		args = append(args, Arg{a.Name, clone(a.Value)})
+	}
+
+	if n := len(slot.Args); n > len(args) {
+		clearArgs(slot.Args[len(args):n])
+	}
+
+	var stack []uintptr
+	if len(e.Stack) != 0 {
+		stack = append(slot.Stack[:0], e.Stack...)
+	}
+
+	*slot = Event{
+		Message: strings.Clone(e.Message),
+		Source:  strings.Clone(e.Source),
+		Format:  strings.Clone(e.Format),
+		Args:    args,
+		Time:    e.Time,
+		Debug:   e.Debug,
+		Level:   e.Level,
+		Stack:   stack,
+	}
+
+	if h.next++; h.next == len(h.ring) {
+		h.next = 0
+	}
+
+	if h.count < len(h.ring) {
+		h.count++
+	}
+}
+
+// events returns copies of the events recorded in the ring, it must be called
+// with the mutex held.
+func (h *RingHandler) events() []*Event {
+	events := make([]*Event, 0, h.count)
+	h.each(func(e *Event) { events = append(events, e.Clone()) })
+	return events
+}
+
+// drain returns copies of the debug events recorded in the ring and empties
+// it, it must be called with the mutex held.
+func (h *RingHandler) drain() []*Event {
+	var events []*Event
+
+	h.each(func(e *Event) {
+		if e.Debug {
+			events = append(events, e.Clone())
+		}
+	})
+
+	h.count = 0
+	return events
+}
+
+// each calls f with the events recorded in the ring, from the oldest to the
+// most recent, it must be called with the mutex held.
+func (h *RingHandler) each(f func(*Event)) {
+	first := h.next - h.count
+
+	if first < 0 {
+		first += len(h.ring)
+	}
+
+	for i := 0; i < h.count; i++ {
+		f(&h.ring[(first+i)%len(h.ring)])
+	}
+}
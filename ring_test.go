@@ -0,0 +1,103 @@
+package events
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRingHandler(t *testing.T) {
+	var messages []string
+
+	h := NewRingHandler(HandlerFunc(func(e *Event) {
+		messages = append(messages, e.Message)
+	}), 3)
+
+	h.HandleEvent(&Event{Message: "debug 1", Debug: true})
+	h.HandleEvent(&Event{Message: "info 1"})
+	h.HandleEvent(&Event{Message: "debug 2", Debug: true})
+	h.HandleEvent(&Event{Message: "debug 3", Debug: true})
+	h.HandleEvent(&Event{Message: "debug 4", Debug: true})
+
+	if !reflect.DeepEqual(messages, []string{"info 1"}) {
+		t.Error("only the events which are not debug events must be passed to the handler:", messages)
+	}
+
+	if events := h.Events(); len(events) != 3 || events[0].Message != "debug 2" || events[2].Message != "debug 4" {
+		t.Errorf("the ring must hold the last 3 events: %v", events)
+	}
+
+	messages = nil
+	h.HandleEvent(&Event{Message: "error", Args: Args{{"error", errors.New("oops!")}}})
+
+	if !reflect.DeepEqual(messages, []string{"debug 3", "debug 4", "error"}) {
+		t.Error("the debug events must be passed to the handler before the triggering event:", messages)
+	}
+
+	if events := h.Events(); len(events) != 0 {
+		t.Errorf("the ring must be empty after being dumped: %v", events)
+	}
+}
+
+func TestRingHandlerDump(t *testing.T) {
+	var messages []string
+
+	h := NewRingHandler(HandlerFunc(func(e *Event) {
+		messages = append(messages, e.Message)
+	}), 0)
+	h.Trigger = func(e *Event) bool { return e.Message == "trigger" }
+
+	h.HandleEvent(&Event{Message: "debug 1", Debug: true})
+	h.HandleEvent(&Event{Message: "error", Level: LevelError})
+	h.Dump()
+	h.Dump()
+
+	if !reflect.DeepEqual(messages, []string{"error", "debug 1"}) {
+		t.Error(messages)
+	}
+
+	messages = nil
+	h.HandleEvent(&Event{Message: "debug 2", Debug: true})
+	h.HandleEvent(&Event{Message: "trigger", Debug: true})
+
+	if !reflect.DeepEqual(messages, []string{"debug 2", "trigger"}) {
+		t.Error(messages)
+	}
+}
+
+func TestRingHandlerRecord(t *testing.T) {
+	h := NewRingHandler(Discard, 1)
+	v := Int(42)
+	s := []int{1, 2, 3}
+
+	h.HandleEvent(&Event{Message: "A", Args: Args{{"a", &v}, {"b", s}, {"c", 3}}, Debug: true})
+	h.HandleEvent(&Event{Message: "B", Args: Args{{"a", 1}}, Debug: true})
+
+	events := h.Events()
+
+	if !reflect.DeepEqual(events, []*Event{{Message: "B", Args: Args{{"a", 1}}, Debug: true}}) {
+		t.Errorf("%#v", events[0])
+	}
+
+	if args := h.ring[0].Args[:3]; args[1] != (Arg{}) || args[2] != (Arg{}) {
+		t.Error("the arguments of the previous events must be cleared:", args)
+	}
+}
+
+func TestRingHandlerStack(t *testing.T) {
+	h := NewRingHandler(Discard, 2)
+	stack := []uintptr{1, 2, 3}
+
+	h.HandleEvent(&Event{Message: "A", Format: "A", Stack: stack, Debug: true})
+	h.HandleEvent(&Event{Message: "B", Debug: true})
+	stack[0] = 0
+
+	events := h.Events()
+
+	if !reflect.DeepEqual(events, []*Event{
+		{Message: "A", Format: "A", Stack: []uintptr{1, 2, 3}, Debug: true},
+		{Message: "B", Debug: true},
+	}) {
+		t.Errorf("the stacks of events must be recorded: %#v", events)
+	}
+}
@@ -0,0 +1,153 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Rule is a routing rule of a Router.
+type Rule struct {
+	// Match selects the events routed to the rule's handler, a nil Match
+	// selects all events. Compiled filter expressions can be used here with
+	// the Expr.Match method.
+	Match func(*Event) bool
+
+	// Handler receives the events selected by the rule, a nil Handler
+	// discards them.
+	Handler Handler
+
+	// Continue controls whether the events selected by the rule are also
+	// evaluated by the following rules. When false, routing stops at the rule.
+	Continue bool
+}
+
+// Router is an event handler which dispatches the events it receives to other
+// handlers according to an ordered list of rules.
+//
+// Each event is evaluated by the rules in order, and passed to the handlers of
+// the rules that select it, until a rule which doesn't have Continue set
+// selects it. Events that no rules selected are passed to the fallback handler.
+//
+// The rules can be replaced at runtime by calling SetRules, routing events
+// doesn't require acquiring locks.
+//
+// It is safe to use a router concurrently from multiple goroutines.
+type Router struct {
+	config atomic.Value // *routerConfig
+}
+
+type routerConfig struct {
+	rules    []Rule
+	fallback Handler
+}
+
+// NewRouter creates a new router which dispatches events according to rules,
+// passing the events that no rules selected to fallback. A nil fallback
+// discards them.
+func NewRouter(fallback Handler, rules ...Rule) *Router {
+	if fallback == nil {
+		fallback = Discard
+	}
+	r := &Router{}
+	r.config.Store(&routerConfig{
+		rules:    copyRules(rules),
+		fallback: fallback,
+	})
+	return r
+}
+
+// HandleEvent satisfies the Handler interface.
+func (r *Router) HandleEvent(e *Event) {
+	c := r.load()
+	matched := false
+
+	for i := range c.rules {
+		rule := &c.rules[i]
+
+		if rule.Match == nil || rule.Match(e) {
+			rule.Handler.HandleEvent(e)
+			matched = true
+
+			if !rule.Continue {
+				break
+			}
+		}
+	}
+
+	if !matched {
+		c.fallback.HandleEvent(e)
+	}
+}
+
+// Rules returns a copy of the router's rules.
+func (r *Router) Rules() []Rule {
+	return copyRules(r.load().rules)
+}
+
+// SetRules atomically replaces the router's rules.
+func (r *Router) SetRules(rules ...Rule) {
+	c := r.load()
+	r.config.Store(&routerConfig{
+		rules:    copyRules(rules),
+		fallback: c.fallback,
+	})
+}
+
+// Flush satisfies the Flusher interface, it flushes the handlers of all rules
+// and the fallback handler.
+func (r *Router) Flush(ctx context.Context) error {
+	var errs []error
+	for _, h := range r.load().handlers() {
+		if err := FlushHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close satisfies the Closer interface, it closes the handlers of all rules and
+// the fallback handler.
+func (r *Router) Close(ctx context.Context) error {
+	var errs []error
+	for _, h := range r.load().handlers() {
+		if err := CloseHandler(ctx, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Router) load() *routerConfig {
+	return r.config.Load().(*routerConfig)
+}
+
+// handlers returns the list of handlers of c, each handler appears only once
+// even if it is used by multiple rules.
+func (c *routerConfig) handlers() []Handler {
+	handlers := make([]Handler, 0, len(c.rules)+1)
+	for _, rule := range c.rules {
+		handlers = appendHandler(handlers, rule.Handler)
+	}
+	return appendHandler(handlers, c.fallback)
+}
+
+func appendHandler(handlers []Handler, h Handler) []Handler {
+	for _, x := range handlers {
+		if sameHandler(x, h) {
+			return handlers
+		}
+	}
+	return append(handlers, h)
+}
+
+func copyRules(rules []Rule) []Rule {
+	c := make([]Rule, len(rules))
+	copy(c, rules)
+	for i := range c {
+		if c[i].Handler == nil {
+			c[i].Handler = Discard
+		}
+	}
+	return c
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	var routes []string
+
+	route := func(name string) Handler {
+		return HandlerFunc(func(e *Event) {
+			routes = append(routes, name+":"+e.Message)
+		})
+	}
+
+	r := NewRouter(route("fallback"),
+		Rule{Match: MustCompileExpr(`error`).Match, Handler: route("errors"), Continue: true},
+		Rule{Match: MustCompileExpr(`args.status >= 100`).Match, Handler: route("access")},
+		Rule{Match: MustCompileExpr(`debug`).Match, Handler: route("debug")},
+		Rule{Handler: route("all")},
+	)
+
+	r.HandleEvent(&Event{Message: "A", Args: Args{{"error", io.EOF}}})
+	r.HandleEvent(&Event{Message: "B", Args: Args{{"error", io.EOF}, {"status", 500}}})
+	r.HandleEvent(&Event{Message: "C", Args: Args{{"status", 200}}})
+	r.HandleEvent(&Event{Message: "D", Debug: true})
+
+	if !reflect.DeepEqual(routes, []string{
+		"errors:A", "all:A",
+		"errors:B", "access:B",
+		"access:C",
+		"debug:D",
+	}) {
+		t.Error("bad routes:", routes)
+	}
+
+	routes = nil
+	r.SetRules(Rule{Match: MustCompileExpr(`debug`).Match, Handler: route("debug")})
+
+	if n := len(r.Rules()); n != 1 {
+		t.Error("bad number of rules:", n)
+	}
+
+	r.HandleEvent(&Event{Message: "A"})
+	r.HandleEvent(&Event{Message: "B", Debug: true})
+
+	if !reflect.DeepEqual(routes, []string{"fallback:A", "debug:B"}) {
+		t.Error("bad routes:", routes)
+	}
+}
+
+func TestRouterFlush(t *testing.T) {
+	h1 := &flushHandler{}
+	h2 := &flushHandler{}
+	r := NewRouter(h2, Rule{Handler: h1}, Rule{Handler: h1}, Rule{Handler: Discard})
+
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if h1.flushed != 1 || h2.flushed != 1 {
+		t.Error("handlers were not flushed exactly once:", h1.flushed, h2.flushed)
+	}
+}
+
+func TestRouterNilHandler(t *testing.T) {
+	n := 0
+	h := HandlerFunc(func(e *Event) { n++ })
+	r := NewRouter(h, Rule{Match: func(e *Event) bool { return e.Debug }})
+	r.HandleEvent(&Event{Debug: true})
+
+	r.SetRules(Rule{Handler: nil, Continue: true})
+	r.HandleEvent(&Event{})
+
+	if n != 0 {
+		t.Error("rules with a nil handler must discard the events they select:", n)
+	}
+}
+
+func TestRouterConcurrentSetRules(t *testing.T) {
+	r := NewRouter(nil)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i != 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i != 1000; i++ {
+				r.HandleEvent(&Event{Message: "Hello World!"})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i != 1000; i++ {
+				r.SetRules(Rule{Handler: Discard})
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkRouter(b *testing.B) {
+	r := NewRouter(Discard,
+		Rule{Match: MustCompileExpr(`error`).Match, Handler: Discard},
+		Rule{Match: MustCompileExpr(`debug`).Match, Handler: Discard},
+	)
+	e := &Event{Message: "Hello Luke!", Args: Args{{"name", "Luke"}}}
+
+	for i := 0; i != b.N; i++ {
+		r.HandleEvent(e)
+	}
+}
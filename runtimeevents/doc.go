@@ -0,0 +1,5 @@
+// Package runtimeevents periodically emits events reporting statistics about
+// the Go runtime of the program (goroutines, heap, garbage collections, file
+// descriptors), giving services a heartbeat stream in their event pipeline
+// without running a separate metrics agent.
+package runtimeevents
@@ -0,0 +1,217 @@
+package runtimeevents
+
+import (
+	"context"
+	"math"
+	"os"
+	"runtime/metrics"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+// DefaultInterval is the interval at which runtime statistics are emitted when
+// none is configured.
+const DefaultInterval = 1 * time.Minute
+
+// Config carries the configuration of the runtime statistics emitter.
+type Config struct {
+	// Logger is the logger producing the events, events.DefaultLogger is used
+	// when nil.
+	Logger *events.Logger
+
+	// Interval is the time between two events, DefaultInterval is used when
+	// zero.
+	Interval time.Duration
+}
+
+// Start emits runtime statistics with the default logger every
+// DefaultInterval, until ctx is canceled.
+func Start(ctx context.Context) {
+	StartWith(ctx, Config{})
+}
+
+// StartWith emits runtime statistics according to config, until ctx is
+// canceled. The statistics are emitted by a background goroutine, StartWith
+// returns immediately.
+//
+// The events have these arguments:
+// - "goroutines" is the number of live goroutines.
+// - "heap_inuse" is the number of bytes in use by the heap.
+// - "gc_count" is the number of garbage collections since the last event.
+// - "gc_pause_p50", "gc_pause_p90", "gc_pause_p99" and "gc_pause_max" are
+// the percentiles of the garbage collection pauses since the last event.
+// - "open_fds" is the number of open file descriptors, it is only reported on
+// systems exposing them in /proc.
+func StartWith(ctx context.Context, config Config) {
+	logger := config.Logger
+	if logger == nil {
+		logger = events.DefaultLogger
+	}
+
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		run(ctx, logger, ticker.C)
+	}()
+}
+
+// run emits runtime statistics with logger each time it receives from ticks,
+// until ctx is canceled.
+func run(ctx context.Context, logger *events.Logger, ticks <-chan time.Time) {
+	c := newCollector()
+	c.collect() // initializes the counters of the first interval
+
+	for {
+		select {
+		case <-ticks:
+			logger.Log("runtime statistics", c.collect())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+const (
+	metricGoroutines  = "/sched/goroutines:goroutines"
+	metricHeapObjects = "/memory/classes/heap/objects:bytes"
+	metricHeapUnused  = "/memory/classes/heap/unused:bytes"
+	metricGCCycles    = "/gc/cycles/total:gc-cycles"
+	metricGCPauses    = "/sched/pauses/total/gc:seconds"
+
+	// metricGCPausesGo121 is the name of the GC pauses metric before Go 1.22,
+	// which deprecated it in favor of metricGCPauses.
+	metricGCPausesGo121 = "/gc/pauses:seconds"
+)
+
+// gcPausesMetric returns the name of the GC pauses metric supported by the
+// runtime.
+func gcPausesMetric() string {
+	for _, d := range metrics.All() {
+		if d.Name == metricGCPauses {
+			return metricGCPauses
+		}
+	}
+	return metricGCPausesGo121
+}
+
+// collector reads runtime metrics, retaining the cumulative values that are
+// reported as differences between two collections.
+type collector struct {
+	samples  []metrics.Sample
+	gcCycles uint64
+	gcPauses []uint64 // bucket counts of the GC pauses histogram
+}
+
+func newCollector() *collector {
+	return &collector{
+		samples: []metrics.Sample{
+			{Name: metricGoroutines},
+			{Name: metricHeapObjects},
+			{Name: metricHeapUnused},
+			{Name: metricGCCycles},
+			{Name: gcPausesMetric()},
+		},
+	}
+}
+
+func (c *collector) collect() events.Args {
+	metrics.Read(c.samples)
+
+	args := events.Args{
+		{Name: "goroutines", Value: uint64Value(c.samples[0].Value)},
+		{Name: "heap_inuse", Value: uint64Value(c.samples[1].Value) + uint64Value(c.samples[2].Value)},
+	}
+
+	gcCycles := uint64Value(c.samples[3].Value)
+	args = append(args, events.Arg{Name: "gc_count", Value: gcCycles - c.gcCycles})
+	c.gcCycles = gcCycles
+
+	if v := c.samples[4].Value; v.Kind() == metrics.KindFloat64Histogram {
+		h := v.Float64Histogram()
+		counts := make([]uint64, len(h.Counts))
+
+		for i, n := range h.Counts {
+			counts[i] = n
+			if i < len(c.gcPauses) {
+				counts[i] -= c.gcPauses[i]
+			}
+		}
+
+		c.gcPauses = append(c.gcPauses[:0], h.Counts...)
+		args = append(args,
+			events.Arg{Name: "gc_pause_p50", Value: percentile(counts, h.Buckets, 0.50)},
+			events.Arg{Name: "gc_pause_p90", Value: percentile(counts, h.Buckets, 0.90)},
+			events.Arg{Name: "gc_pause_p99", Value: percentile(counts, h.Buckets, 0.99)},
+			events.Arg{Name: "gc_pause_max", Value: percentile(counts, h.Buckets, 1)},
+		)
+	}
+
+	if n, ok := openFiles(); ok {
+		args = append(args, events.Arg{Name: "open_fds", Value: n})
+	}
+
+	return args
+}
+
+func uint64Value(v metrics.Value) uint64 {
+	if v.Kind() == metrics.KindUint64 {
+		return v.Uint64()
+	}
+	return 0
+}
+
+// percentile returns the p-th percentile of the distribution of a histogram
+// of durations in seconds, as the upper bound of the bucket it falls in. The
+// boundaries of bucket i are buckets[i] and buckets[i+1].
+func percentile(counts []uint64, buckets []float64, p float64) time.Duration {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(p * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var sum uint64
+	for i, n := range counts {
+		if sum += n; sum >= rank {
+			upper := buckets[i+1]
+			if math.IsInf(upper, +1) {
+				upper = buckets[i]
+			}
+			return time.Duration(upper * float64(time.Second))
+		}
+	}
+
+	return 0
+}
+
+// openFiles returns the number of open file descriptors of the process, the
+// boolean is false if the system doesn't expose them in /proc.
+func openFiles() (int, bool) {
+	f, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return 0, false
+	}
+
+	// The directory opened to list the file descriptors is not counted.
+	return len(names) - 1, true
+}
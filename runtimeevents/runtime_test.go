@@ -0,0 +1,101 @@
+package runtimeevents
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+func TestRun(t *testing.T) {
+	evchan := make(chan *events.Event, 10)
+	logger := events.NewLogger(events.HandlerFunc(func(e *events.Event) {
+		evchan <- e.Clone()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		run(ctx, logger, ticks)
+	}()
+
+	runtime.GC()
+	ticks <- time.Now()
+	e := <-evchan
+
+	cancel()
+	<-done
+
+	if n := len(evchan); n != 0 {
+		t.Error("bad number of events:", n+1)
+	}
+
+	if e.Message != "runtime statistics" {
+		t.Error("bad message:", e.Message)
+	}
+
+	for _, name := range []string{
+		"goroutines",
+		"heap_inuse",
+		"gc_count",
+		"gc_pause_p50",
+		"gc_pause_p90",
+		"gc_pause_p99",
+		"gc_pause_max",
+	} {
+		if _, ok := e.Args.Get(name); !ok {
+			t.Error("missing argument:", name)
+		}
+	}
+
+	if v, _ := e.Args.Get("goroutines"); v.(uint64) == 0 {
+		t.Error("the number of goroutines must not be zero")
+	}
+}
+
+func TestCollectorGC(t *testing.T) {
+	c := newCollector()
+	c.collect()
+
+	runtime.GC()
+	runtime.GC()
+	args := c.collect()
+
+	if v, _ := args.Get("gc_count"); v.(uint64) < 2 {
+		t.Error("bad gc_count:", v)
+	}
+
+	if v, _ := args.Get("gc_pause_max"); v.(time.Duration) <= 0 {
+		t.Error("bad gc_pause_max:", v)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	buckets := []float64{0, 0.001, 0.002, 0.004}
+	counts := []uint64{50, 40, 10}
+
+	tests := []struct {
+		p float64
+		d time.Duration
+	}{
+		{0.50, 1 * time.Millisecond},
+		{0.90, 2 * time.Millisecond},
+		{0.99, 4 * time.Millisecond},
+		{1.00, 4 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		if d := percentile(counts, buckets, test.p); d != test.d {
+			t.Errorf("p%v: %v != %v", test.p*100, d, test.d)
+		}
+	}
+
+	if d := percentile(make([]uint64, 3), buckets, 0.5); d != 0 {
+		t.Error("empty histograms must report zero:", d)
+	}
+}
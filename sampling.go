@@ -0,0 +1,227 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingRate configures how many events are kept by a SamplingHandler for
+// each key in a sampling interval. The zero-value keeps all events.
+type SamplingRate struct {
+	// First is the number of events always passed to the handler.
+	First int
+
+	// Thereafter is the base of the logarithmic sampling applied once First
+	// events were seen: events number First+1, First+M, First+M^2, ... are
+	// passed to the handler, where M is the value of Thereafter. Values lower
+	// than 2 discard all events after the first ones.
+	Thereafter int
+}
+
+// keep returns true if the n-th event seen in an interval must be kept.
+func (r SamplingRate) keep(n int) bool {
+	if r == (SamplingRate{}) || n <= r.First {
+		return true
+	}
+	if r.Thereafter < 2 {
+		return false
+	}
+	n -= r.First
+	for n%r.Thereafter == 0 {
+		n /= r.Thereafter
+	}
+	return n == 1
+}
+
+// SamplingHandler is an event handler which limits the number of events passed
+// to the handler it wraps. Events are grouped by key, and each key has its own
+// budget of events per sampling interval.
+//
+// Events passed to the handler after some were discarded carry an extra
+// "suppressed" argument set to the number of events that were discarded since
+// the last one was kept.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type SamplingHandler struct {
+	// Handler is the event handler receiving the sampled events.
+	Handler Handler
+
+	// Interval is the duration of sampling intervals, it defaults to one
+	// second when zero.
+	Interval time.Duration
+
+	// Rate is the sampling rate applied to events, events are not sampled
+	// when it is the zero-value.
+	Rate SamplingRate
+
+	// DebugRate is the sampling rate applied to events at the LevelDebug
+	// level, Rate is used when DebugRate is the zero-value.
+	DebugRate SamplingRate
+
+	// ErrorRate is the sampling rate applied to events at the LevelError level
+	// (which includes events carrying errors), Rate is used when ErrorRate is
+	// the zero-value.
+	ErrorRate SamplingRate
+
+	// Key returns the key of the sampling group that an event belongs to.
+	// When nil, events are grouped by source (which identifies the call site
+	// and therefore the message template), or by the format string of their
+	// message if they have no source (see Event.Format), or by message if
+	// they have neither.
+	Key func(*Event) string
+
+	mutex    sync.Mutex
+	start    time.Time
+	counters map[string]*samplingCounter
+}
+
+type samplingCounter struct {
+	seen       int // number of events seen in the current interval
+	suppressed int // number of events discarded since the last one was kept
+}
+
+// NewSamplingHandler creates a new sampling handler which passes events to
+// handler, applying rate to each key in intervals of the given duration.
+func NewSamplingHandler(handler Handler, interval time.Duration, rate SamplingRate) *SamplingHandler {
+	return &SamplingHandler{
+		Handler:  handler,
+		Interval: interval,
+		Rate:     rate,
+	}
+}
+
+// HandleEvent satisfies the Handler interface.
+func (h *SamplingHandler) HandleEvent(e *Event) {
+	now := e.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	rate := h.rate(e)
+	key := h.key(e)
+
+	h.mutex.Lock()
+	h.advance(now)
+
+	c := h.counters[key]
+	if c == nil {
+		c = &samplingCounter{}
+		// The key may reference memory owned by the event, which the handler
+		// must not retain.
+		h.counters[strings.Clone(key)] = c
+	}
+
+	c.seen++
+
+	if !rate.keep(c.seen) {
+		c.suppressed++
+		h.mutex.Unlock()
+		return
+	}
+
+	suppressed := c.suppressed
+	c.suppressed = 0
+	h.mutex.Unlock()
+
+	if suppressed == 0 {
+		h.Handler.HandleEvent(e)
+		return
+	}
+
+	s := samplingPool.Get().(*Event)
+	*s = Event{
+		Message: e.Message,
+		Source:  e.Source,
+		Format:  e.Format,
+		Args:    append(append(s.Args[:0], e.Args...), Arg{"suppressed", suppressed}),
+		Time:    e.Time,
+		Debug:   e.Debug,
+		Level:   e.Level,
+		Stack:   e.Stack,
+	}
+
+	h.Handler.HandleEvent(s)
+
+	// don't hold pointers to let the garbage collector free the objects
+	for i := range s.Args {
+		s.Args[i] = Arg{}
+	}
+
+	*s = Event{Args: s.Args[:0]}
+	samplingPool.Put(s)
+}
+
+// Flush satisfies the Flusher interface.
+func (h *SamplingHandler) Flush(ctx context.Context) error {
+	return FlushHandler(ctx, h.Handler)
+}
+
+// Close satisfies the Closer interface.
+func (h *SamplingHandler) Close(ctx context.Context) error {
+	return CloseHandler(ctx, h.Handler)
+}
+
+func (h *SamplingHandler) rate(e *Event) SamplingRate {
+	switch e.Severity() {
+	case LevelDebug:
+		if h.DebugRate != (SamplingRate{}) {
+			return h.DebugRate
+		}
+	case LevelError:
+		if h.ErrorRate != (SamplingRate{}) {
+			return h.ErrorRate
+		}
+	}
+	return h.Rate
+}
+
+func (h *SamplingHandler) key(e *Event) string {
+	if h.Key != nil {
+		return h.Key(e)
+	}
+	if len(e.Source) != 0 {
+		return e.Source
+	}
+	if len(e.Format) != 0 {
+		return e.Format
+	}
+	return e.Message
+}
+
+// advance must be called with the mutex held, it starts a new sampling interval
+// if the current one has expired.
+func (h *SamplingHandler) advance(now time.Time) {
+	interval := h.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	// Events are not always received in chronological order (their time is
+	// set before concurrent producers reach the handler), events older than
+	// the start of the interval are accounted for in the current one.
+	if h.counters != nil && now.Before(h.start.Add(interval)) {
+		return
+	}
+
+	if h.counters == nil {
+		h.counters = make(map[string]*samplingCounter)
+	}
+
+	// Counters that still have suppressed events are retained so the count is
+	// reported on the next event that gets kept.
+	for key, c := range h.counters {
+		if c.suppressed == 0 {
+			delete(h.counters, key)
+		} else {
+			c.seen = 0
+		}
+	}
+
+	h.start = now
+}
+
+var samplingPool = sync.Pool{
+	New: func() interface{} { return &Event{Args: make(Args, 0, 8)} },
+}
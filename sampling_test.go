@@ -0,0 +1,172 @@
+package events
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSamplingRate(t *testing.T) {
+	rate := SamplingRate{First: 2, Thereafter: 3}
+	kept := []int{}
+
+	for i := 1; i <= 30; i++ {
+		if rate.keep(i) {
+			kept = append(kept, i)
+		}
+	}
+
+	if !reflect.DeepEqual(kept, []int{1, 2, 3, 5, 11, 29}) {
+		t.Error("bad events kept:", kept)
+	}
+}
+
+func TestSamplingRateZero(t *testing.T) {
+	for i := 1; i <= 30; i++ {
+		if !(SamplingRate{}).keep(i) {
+			t.Fatal("the zero-value must keep all events, discarded event", i)
+		}
+	}
+}
+
+func TestSamplingHandler(t *testing.T) {
+	var evlist []*Event
+	now := time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC)
+
+	h := NewSamplingHandler(HandlerFunc(func(e *Event) {
+		evlist = append(evlist, e.Clone())
+	}), time.Second, SamplingRate{First: 1, Thereafter: 2})
+
+	h.DebugRate = SamplingRate{First: 1}
+	h.ErrorRate = SamplingRate{First: 100}
+
+	for i := 0; i != 5; i++ {
+		h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now})
+		h.HandleEvent(&Event{Message: "B", Source: "b.go:1", Time: now, Debug: true})
+		h.HandleEvent(&Event{Message: "C", Source: "c.go:1", Time: now, Args: Args{{"error", errors.New("C")}}})
+	}
+
+	// Starts a new interval, the suppressed events of A and B get reported.
+	now = now.Add(time.Second)
+	h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now})
+	h.HandleEvent(&Event{Message: "B", Source: "b.go:1", Time: now, Debug: true})
+
+	messages := []string{}
+	suppressed := []interface{}{}
+
+	for _, e := range evlist {
+		v, _ := e.Args.Get("suppressed")
+		messages = append(messages, e.Message)
+		suppressed = append(suppressed, v)
+	}
+
+	if !reflect.DeepEqual(messages, []string{
+		"A", "B", "C", "A", "C", "A", "C", "C", "A", "C",
+		"A", "B",
+	}) {
+		t.Error("bad messages:", messages)
+	}
+
+	if !reflect.DeepEqual(suppressed, []interface{}{
+		nil, nil, nil, nil, nil, nil, nil, nil, 1, nil,
+		nil, 4,
+	}) {
+		t.Error("bad suppressed counts:", suppressed)
+	}
+}
+
+func TestSamplingHandlerOutOfOrder(t *testing.T) {
+	n := 0
+	now := time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC)
+	h := NewSamplingHandler(HandlerFunc(func(e *Event) { n++ }), time.Second, SamplingRate{First: 2})
+
+	// Events produced concurrently may reach the handler with times slightly
+	// earlier than the start of the interval, they must not reset it.
+	for i := 0; i != 10; i++ {
+		h.HandleEvent(&Event{Message: "A", Time: now.Add(-time.Duration(i%2) * time.Millisecond)})
+	}
+
+	if n != 2 {
+		t.Error("bad count of events passed to the handler:", n)
+	}
+
+	h.HandleEvent(&Event{Message: "A", Time: now.Add(999 * time.Millisecond)})
+
+	if n != 2 {
+		t.Error("events within the interval must be sampled:", n)
+	}
+
+	h.HandleEvent(&Event{Message: "A", Time: now.Add(time.Second)})
+
+	if n != 3 {
+		t.Error("events past the end of the interval must start a new one:", n)
+	}
+}
+
+func TestSamplingHandlerFormatKey(t *testing.T) {
+	var messages []string
+	h := NewSamplingHandler(HandlerFunc(func(e *Event) {
+		messages = append(messages, e.Clone().Message)
+	}), time.Minute, SamplingRate{First: 1})
+
+	// Loggers don't report the source of events by default, events must then
+	// be grouped by format string and not by formatted message.
+	l := NewLogger(h)
+	l.EnableSource = false
+
+	for i := 0; i != 3; i++ {
+		l.Log("request %{id}d failed", i)
+		l.Log("retrying")
+	}
+
+	if !reflect.DeepEqual(messages, []string{"request 0 failed", "retrying"}) {
+		t.Error("bad messages:", messages)
+	}
+}
+
+func BenchmarkSamplingHandler(b *testing.B) {
+	h := NewSamplingHandler(Discard, time.Second, SamplingRate{First: 10, Thereafter: 10})
+	e := &Event{
+		Message: "Hello Luke!",
+		Source:  "github.com/segmentio/events/sampling_test.go:42",
+		Args:    Args{{"name", "Luke"}, {"from", "Han"}},
+		Time:    time.Now(),
+	}
+
+	for i := 0; i != b.N; i++ {
+		h.HandleEvent(e)
+	}
+}
+
+func TestSamplingHandlerStack(t *testing.T) {
+	var evlist []*Event
+	now := time.Date(2017, 1, 1, 23, 42, 0, 0, time.UTC)
+	stack := []uintptr{1, 2, 3}
+
+	h := NewSamplingHandler(HandlerFunc(func(e *Event) {
+		evlist = append(evlist, e.Clone())
+	}), time.Second, SamplingRate{First: 1})
+
+	for i := 0; i != 3; i++ {
+		h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now, Stack: stack})
+	}
+
+	// The suppressed events are reported with the first event of the next
+	// interval, which must retain its stack.
+	h.HandleEvent(&Event{Message: "A", Source: "a.go:1", Time: now.Add(time.Second), Stack: stack})
+
+	if len(evlist) != 2 {
+		t.Fatal("bad number of events:", len(evlist))
+	}
+
+	for _, e := range evlist {
+		if !reflect.DeepEqual(e.Stack, stack) {
+			t.Errorf("bad stack: %v", e.Stack)
+		}
+	}
+
+	if v, _ := evlist[1].Args.Get("suppressed"); v != 2 {
+		t.Error("bad suppressed count:", v)
+	}
+}
@@ -0,0 +1,257 @@
+package events
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is the time given to programs to drain after the
+// first shutdown signal, used when ShutdownOptions.Timeout is zero.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownOptions configures the behavior of a Shutdown coordinator.
+type ShutdownOptions struct {
+	// Logger produces the events reporting the steps of the shutdown, when
+	// nil, DefaultLogger is used.
+	Logger *Logger
+
+	// Timeout is the time given to the program to drain after the first
+	// signal, when zero, DefaultShutdownTimeout is used.
+	Timeout time.Duration
+
+	// Signals triggering the shutdown, when empty, SIGTERM and SIGINT are
+	// used.
+	Signals []os.Signal
+
+	// Exit is called to terminate the program when the shutdown is forced,
+	// with the exit status of the cause of the shutdown (see ExitCode), when
+	// nil, os.Exit is used.
+	Exit func(code int)
+}
+
+// Shutdown coordinates the graceful shutdown of programs.
+//
+// The first signal received cancels the context returned by the Context
+// method, with a *SignalError as cause (see context.Cause), and starts the
+// drain deadline. The program is expected to stop its work and call Wait,
+// which runs the registered shutdown hooks in order. If a second signal is
+// received, or the hooks don't complete before the deadline, the program is
+// forced to exit.
+//
+// Each step of the shutdown emits an event, and the handler of the logger is
+// flushed after the hooks completed or before forcing the program to exit.
+//
+// The coordinator watches for signals until the shutdown completes, programs
+// that discard it before (like tests) must call Stop to release it.
+type Shutdown struct {
+	logger  *Logger
+	timeout time.Duration
+	exit    func(int)
+	pc      uintptr
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	// The started channel is closed after the deadline was set, the done
+	// channel is closed after the shutdown hooks completed. The stop channel
+	// is closed by Stop, the exited channel when the run goroutine returns.
+	deadline time.Time
+	started  chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+	exited   chan struct{}
+
+	mutex sync.Mutex
+	hooks []shutdownHook
+}
+
+type shutdownHook struct {
+	name string
+	pc   uintptr
+	fn   func(context.Context) error
+}
+
+// NewShutdown returns a Shutdown coordinator for the program, watching for the
+// signals configured in opts. The context returned by the Context method of
+// the coordinator is a child of ctx, canceling ctx also starts the shutdown.
+func NewShutdown(ctx context.Context, opts ShutdownOptions) *Shutdown {
+	s := &Shutdown{
+		logger:  opts.Logger,
+		timeout: opts.Timeout,
+		exit:    opts.Exit,
+		started: make(chan struct{}),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+
+	if s.logger == nil {
+		s.logger = DefaultLogger
+	}
+
+	if s.timeout == 0 {
+		s.timeout = DefaultShutdownTimeout
+	}
+
+	if s.exit == nil {
+		s.exit = os.Exit
+	}
+
+	if s.logger.EnableSource {
+		s.pc = s.logger.caller(1)
+	}
+
+	signals := opts.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+
+	sigchan := make(chan os.Signal, 2)
+	signal.Notify(sigchan, signals...)
+
+	s.ctx, s.cancel = context.WithCancelCause(ctx)
+	go s.run(sigchan)
+	return s
+}
+
+// Context returns the context canceled when the shutdown starts.
+func (s *Shutdown) Context() context.Context {
+	return s.ctx
+}
+
+// Register adds a hook to the list of functions called by Wait, hooks are
+// called in the order they were registered. The context passed to hooks
+// expires at the drain deadline.
+func (s *Shutdown) Register(name string, hook func(context.Context) error) {
+	var pc uintptr
+
+	if s.logger.EnableSource {
+		pc = s.logger.caller(1)
+	}
+
+	s.mutex.Lock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, pc: pc, fn: hook})
+	s.mutex.Unlock()
+}
+
+// Cancel starts the shutdown as if a signal had been received, the cause of
+// the context cancellation is context.Canceled.
+func (s *Shutdown) Cancel() {
+	s.cancel(context.Canceled)
+}
+
+// Wait blocks until the shutdown starts, runs the shutdown hooks, and flushes
+// the handler of the logger. The method returns the cause of the shutdown.
+//
+// Wait may be called multiple times, the hooks only run once.
+func (s *Shutdown) Wait() error {
+	<-s.started
+	s.once.Do(s.drain)
+	<-s.done
+	return context.Cause(s.ctx)
+}
+
+// Stop releases the coordinator: it stops watching for signals and won't force
+// the program to exit anymore. If the shutdown had not started, the context is
+// canceled with context.Canceled as cause, and Wait returns without running the
+// shutdown hooks.
+//
+// Stop may be called multiple times, it returns after the signal handlers were
+// uninstalled.
+func (s *Shutdown) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.exited
+}
+
+func (s *Shutdown) run(sigchan chan os.Signal) {
+	defer close(s.exited)
+	defer signal.Stop(sigchan)
+
+	select {
+	case sig := <-sigchan:
+		s.log(LevelInfo, "shutting down after receiving %{signal}s", sig)
+		s.cancel(&SignalError{Signal: sig})
+	case <-s.ctx.Done():
+		s.log(LevelInfo, "shutting down: %{cause}v", context.Cause(s.ctx))
+	case <-s.stop:
+		s.cancel(context.Canceled)
+		s.once.Do(func() { close(s.done) })
+		close(s.started)
+		return
+	}
+
+	s.deadline = time.Now().Add(s.timeout)
+	close(s.started)
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case sig := <-sigchan:
+		s.log(LevelError, "forcing exit after receiving %{signal}s during shutdown", sig)
+	case <-timer.C:
+		s.log(LevelError, "forcing exit after the shutdown did not complete in %{timeout}v", s.timeout)
+	case <-s.done:
+		return
+	case <-s.stop:
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRecoverFlushTimeout)
+	defer cancel()
+	flushLogger(ctx, s.logger)
+	s.exit(ExitCode(context.Cause(s.ctx)))
+}
+
+func (s *Shutdown) drain() {
+	defer close(s.done)
+
+	ctx, cancel := context.WithDeadline(context.Background(), s.deadline)
+	defer cancel()
+
+	s.mutex.Lock()
+	hooks := append([]shutdownHook(nil), s.hooks...)
+	s.mutex.Unlock()
+
+	for _, hook := range hooks {
+		start := time.Now()
+
+		if err := hook.fn(ctx); err != nil {
+			s.logPC(hook.pc, LevelError, "shutdown hook %{hook}s failed after %{duration}v: %{error}v", hook.name, time.Since(start), err)
+		} else {
+			s.logPC(hook.pc, LevelInfo, "shutdown hook %{hook}s completed in %{duration}v", hook.name, time.Since(start))
+		}
+	}
+
+	s.log(LevelInfo, "shutdown complete")
+
+	// The deadline of the hooks may have expired already, the handler is given
+	// its own time to flush the events.
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), DefaultRecoverFlushTimeout)
+	defer flushCancel()
+	flushLogger(flushCtx, s.logger)
+}
+
+func (s *Shutdown) log(level Level, format string, args ...interface{}) {
+	s.logPC(s.pc, level, format, args...)
+}
+
+func (s *Shutdown) logPC(pc uintptr, level Level, format string, args ...interface{}) {
+	s.logger.logPC(nil, 1, pc, false, level, format, args...)
+}
+
+// flushLogger flushes the handler of logger, or DefaultHandler if the logger
+// has no handler.
+func flushLogger(ctx context.Context, logger *Logger) {
+	handler := logger.Handler
+	if handler == nil {
+		handler = DefaultHandler
+	}
+	FlushHandler(ctx, handler)
+}
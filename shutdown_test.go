@@ -0,0 +1,256 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// shutdownRecorder captures the events and exit code of a Shutdown, it is safe
+// to use concurrently since events are produced by background goroutines.
+type shutdownRecorder struct {
+	mutex   sync.Mutex
+	events  []*Event
+	flushes []error // errors of the contexts passed to Flush
+	code    chan int
+}
+
+func newShutdownRecorder() *shutdownRecorder {
+	return &shutdownRecorder{code: make(chan int, 1)}
+}
+
+func (r *shutdownRecorder) HandleEvent(e *Event) {
+	r.mutex.Lock()
+	r.events = append(r.events, e.Clone())
+	r.mutex.Unlock()
+}
+
+func (r *shutdownRecorder) Flush(ctx context.Context) error {
+	r.mutex.Lock()
+	r.flushes = append(r.flushes, ctx.Err())
+	r.mutex.Unlock()
+	return nil
+}
+
+func (r *shutdownRecorder) exit(code int) { r.code <- code }
+
+func (r *shutdownRecorder) messages() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var messages []string
+	for _, e := range r.events {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func (r *shutdownRecorder) options(timeout time.Duration) ShutdownOptions {
+	return ShutdownOptions{
+		Logger:  NewLogger(r),
+		Timeout: timeout,
+		Signals: []os.Signal{syscall.SIGHUP},
+		Exit:    r.exit,
+	}
+}
+
+func sendSignal(t *testing.T, sig os.Signal) {
+	p, _ := os.FindProcess(os.Getpid())
+	if err := p.Signal(sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	r := newShutdownRecorder()
+	s := NewShutdown(context.Background(), r.options(time.Minute))
+	defer s.Stop()
+
+	var order []string
+	s.Register("A", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("the context of hooks must have a deadline")
+		}
+		order = append(order, "A")
+		return nil
+	})
+	s.Register("B", func(ctx context.Context) error {
+		order = append(order, "B")
+		return errors.New("oops")
+	})
+
+	sendSignal(t, syscall.SIGHUP)
+
+	select {
+	case <-s.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context was not canceled after receiving a signal")
+	}
+
+	if err := s.Wait(); !IsSignal(err, syscall.SIGHUP) {
+		t.Error("bad cause:", err)
+	}
+
+	if cause := context.Cause(s.Context()); !IsSignal(cause, syscall.SIGHUP) {
+		t.Error("bad context cause:", cause)
+	}
+
+	if len(order) != 2 || order[0] != "A" || order[1] != "B" {
+		t.Error("hooks must run once, in order:", order)
+	}
+
+	s.Wait() // hooks must not run again
+
+	if len(order) != 2 {
+		t.Error("hooks ran more than once:", order)
+	}
+
+	messages := r.messages()
+	expect := []string{
+		"shutting down after receiving hangup",
+		"shutdown hook A completed in",
+		"shutdown hook B failed after",
+		"shutdown complete",
+	}
+
+	if len(messages) != len(expect) {
+		t.Fatal("bad events:", messages)
+	}
+
+	for i := range expect {
+		if len(messages[i]) < len(expect[i]) || messages[i][:len(expect[i])] != expect[i] {
+			t.Errorf("bad event #%d: %q", i, messages[i])
+		}
+	}
+
+	select {
+	case code := <-r.code:
+		t.Error("the program must not exit after a graceful shutdown:", code)
+	default:
+	}
+}
+
+func TestShutdownCancel(t *testing.T) {
+	r := newShutdownRecorder()
+	s := NewShutdown(context.Background(), r.options(time.Minute))
+	defer s.Stop()
+	s.Cancel()
+
+	if err := s.Wait(); err != context.Canceled {
+		t.Error("bad cause:", err)
+	}
+}
+
+func TestShutdownForceExit(t *testing.T) {
+	t.Run("second signal", func(t *testing.T) {
+		r := newShutdownRecorder()
+		s := NewShutdown(context.Background(), r.options(time.Minute))
+		defer s.Stop()
+		release := make(chan struct{})
+		s.Register("block", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		defer close(release)
+
+		sendSignal(t, syscall.SIGHUP)
+		<-s.Context().Done()
+		go s.Wait()
+		sendSignal(t, syscall.SIGHUP)
+
+		select {
+		case code := <-r.code:
+			if code != 128+int(syscall.SIGHUP) {
+				t.Error("bad exit code:", code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the program was not forced to exit after a second signal")
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		r := newShutdownRecorder()
+		s := NewShutdown(context.Background(), r.options(10*time.Millisecond))
+		defer s.Stop()
+		release := make(chan struct{})
+		s.Register("block", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		defer close(release)
+
+		s.Cancel()
+		go s.Wait()
+
+		select {
+		case code := <-r.code:
+			if code != 1 {
+				t.Error("bad exit code:", code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the program was not forced to exit after the deadline expired")
+		}
+	})
+}
+
+func TestShutdownFlushAfterDeadline(t *testing.T) {
+	r := newShutdownRecorder()
+	s := NewShutdown(context.Background(), r.options(10*time.Millisecond))
+	defer s.Stop()
+
+	s.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	s.Cancel()
+	s.Wait()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.flushes) == 0 {
+		t.Fatal("the handler was not flushed")
+	}
+
+	for _, err := range r.flushes {
+		if err != nil {
+			t.Error("the handler was flushed with an expired context:", err)
+		}
+	}
+}
+
+func TestShutdownStop(t *testing.T) {
+	r := newShutdownRecorder()
+	s := NewShutdown(context.Background(), r.options(time.Minute))
+
+	called := false
+	s.Register("hook", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	s.Stop()
+	s.Stop()
+
+	select {
+	case <-s.Context().Done():
+	default:
+		t.Error("the context must be canceled after Stop")
+	}
+
+	if err := s.Wait(); err != context.Canceled {
+		t.Error("bad cause:", err)
+	}
+
+	if called {
+		t.Error("the hooks must not run after Stop")
+	}
+
+	if messages := r.messages(); len(messages) != 0 {
+		t.Error("no events must be emitted after Stop:", messages)
+	}
+}
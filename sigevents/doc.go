@@ -1,3 +1,28 @@
-// Package sigevents installs signal handlers for SIGUSR1 and SIGUSR2, enabling
-// debug logs when the former is received, or disabling them on the latter.
+// Package sigevents installs signal handlers which enable or disable debug
+// events at runtime, by default debug events of events.DefaultLogger are
+// enabled when SIGUSR1 is received and disabled on SIGUSR2:
+//
+//	uninstall := sigevents.Install(sigevents.Options{})
+//	defer uninstall()
+//
+// The signals and the targets being toggled are configurable, targets may be
+// loggers, slog level variables, or debug filters (see Options).
+//
+// When the EVENTS_DEBUG environment variable is set, enabling debug events of
+// the default targets also reloads the spec of events.DefaultDebugFilter,
+// which makes it possible to change the packages that produce debug logs at
+// runtime by pointing the variable to a file (see events.LoadDebugFilter).
+// Disabling debug events clears the rules of the filter.
+//
+// Earlier versions of the package installed the handlers when it was
+// imported. Programs which only imported it for that side effect must now
+// call Install, otherwise SIGUSR1 and SIGUSR2 are not handled and terminate
+// the program:
+//
+//	import _ "github.com/segmentio/events/v2/sigevents" // before
+//
+//	sigevents.Install(sigevents.Options{}) // after, in main
+//
+// The package can also install signal handlers which emit the stacks of all
+// goroutines as events without terminating the program (see InstallDump).
 package sigevents
@@ -0,0 +1,301 @@
+package sigevents
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+// Goroutine is the record of a goroutine parsed from a goroutine dump.
+type Goroutine struct {
+	// ID is the goroutine identifier.
+	ID int64 `json:"id"`
+
+	// State describes what the goroutine was doing, for example "running",
+	// "chan receive" or "IO wait".
+	State string `json:"state"`
+
+	// Wait is the time that the goroutine has been blocked for, the runtime
+	// only reports it with a resolution of one minute.
+	Wait time.Duration `json:"wait,omitempty"`
+
+	// Locked is true if the goroutine was locked to its OS thread.
+	Locked bool `json:"locked,omitempty"`
+
+	// Frames are the stack frames of the goroutine, starting with the
+	// innermost one.
+	Frames []Frame `json:"frames"`
+
+	// CreatedBy is the location of the go statement which created the
+	// goroutine, it is nil for the main goroutine.
+	CreatedBy *Frame `json:"created_by,omitempty"`
+}
+
+// Frame is a stack frame of a goroutine.
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// String satisfies the fmt.Stringer interface.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s %s:%d", f.Func, f.File, f.Line)
+}
+
+// Goroutines returns the records of all goroutines of the program.
+func Goroutines() []Goroutine {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	// The dump was produced by the runtime, it is always well-formed.
+	goroutines, _ := ParseGoroutines(buf)
+	return goroutines
+}
+
+// ParseGoroutines parses a goroutine dump in the format produced by
+// runtime.Stack or by the runtime when the program crashes.
+func ParseGoroutines(dump []byte) ([]Goroutine, error) {
+	var goroutines []Goroutine
+	var g *Goroutine
+	var fn string
+	var creator bool
+
+	s := bufio.NewScanner(bytes.NewReader(dump))
+	s.Buffer(nil, 1024*1024)
+
+	for lineno := 1; s.Scan(); lineno++ {
+		line := s.Text()
+
+		switch {
+		case len(line) == 0:
+			// Goroutines are separated by empty lines.
+
+		case strings.HasPrefix(line, "goroutine "):
+			r, err := parseGoroutineHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("sigevents: line %d: %w", lineno, err)
+			}
+			goroutines = append(goroutines, r)
+			g, fn = &goroutines[len(goroutines)-1], ""
+
+		case g == nil:
+			return nil, fmt.Errorf("sigevents: line %d: expected goroutine header: %q", lineno, line)
+
+		case line[0] == '\t':
+			// Lines starting with a tab hold the location of the function on
+			// the previous line, or notes of the runtime which are skipped.
+			if fn == "" {
+				break
+			}
+
+			f, err := parseFrameLocation(fn, line[1:])
+			if err != nil {
+				return nil, fmt.Errorf("sigevents: line %d: %w", lineno, err)
+			}
+
+			if creator {
+				g.CreatedBy = &f
+			} else {
+				g.Frames = append(g.Frames, f)
+			}
+			fn = ""
+
+		case strings.HasPrefix(line, "created by "):
+			fn, creator = strings.TrimPrefix(line, "created by "), true
+			if i := strings.Index(fn, " in goroutine "); i >= 0 {
+				fn = fn[:i]
+			}
+
+		case strings.HasPrefix(line, "..."):
+			// "...additional frames elided..."
+
+		default:
+			fn, creator = line, false
+			if i := strings.LastIndexByte(fn, '('); i > 0 {
+				fn = fn[:i]
+			}
+		}
+	}
+
+	return goroutines, s.Err()
+}
+
+// parseGoroutineHeader parses lines like "goroutine 7 [chan receive, 2 minutes]:".
+func parseGoroutineHeader(line string) (Goroutine, error) {
+	var g Goroutine
+
+	id, rest, _ := strings.Cut(strings.TrimPrefix(line, "goroutine "), " ")
+	i := strings.IndexByte(rest, '[')
+	j := strings.LastIndex(rest, "]:")
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || i < 0 || j < i {
+		return g, fmt.Errorf("malformed goroutine header: %q", line)
+	}
+
+	g.ID = n
+	g.State, rest, _ = strings.Cut(rest[i+1:j], ", ")
+
+	for rest != "" {
+		var attr string
+		attr, rest, _ = strings.Cut(rest, ", ")
+
+		switch {
+		case attr == "locked to thread":
+			g.Locked = true
+		case strings.HasSuffix(attr, " minutes"):
+			if m, err := strconv.Atoi(strings.TrimSuffix(attr, " minutes")); err == nil {
+				g.Wait = time.Duration(m) * time.Minute
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// parseFrameLocation parses locations like "/src/main.go:42 +0x1d".
+func parseFrameLocation(fn string, loc string) (Frame, error) {
+	if i := strings.LastIndex(loc, " +0x"); i >= 0 {
+		loc = loc[:i]
+	}
+
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return Frame{}, fmt.Errorf("malformed frame location: %q", loc)
+	}
+
+	line, err := strconv.Atoi(loc[i+1:])
+	if err != nil {
+		return Frame{}, fmt.Errorf("malformed frame location: %q", loc)
+	}
+
+	return Frame{Func: fn, File: loc[:i], Line: line}, nil
+}
+
+// DumpOptions configures the signal handlers installed by InstallDump.
+type DumpOptions struct {
+	// Signals is the list of signals triggering goroutine dumps, it must not
+	// be empty. There is no default since the signals commonly used for this
+	// purpose already have a meaning (SIGQUIT terminates the program, SIGUSR1
+	// and SIGUSR2 toggle debug events, see Install).
+	Signals []os.Signal
+
+	// Logger produces the events of goroutine dumps, when nil,
+	// events.DefaultLogger is used.
+	Logger *events.Logger
+
+	// Single controls whether the goroutines are reported in a single event
+	// instead of one event per goroutine.
+	Single bool
+}
+
+// InstallDump installs signal handlers which capture the stacks of all
+// goroutines and emit them as events when the program receives one of the
+// signals configured in opts, so goroutine dumps can be taken from programs
+// without terminating them, and land in the same place as their other events.
+//
+// Each signal is reported by an event before the dump, like events.Signal does,
+// with the caller of InstallDump as source.
+//
+// The returned function uninstalls the signal handlers. The function panics if
+// no signals were configured.
+func InstallDump(opts DumpOptions) (uninstall func()) {
+	signals := opts.Signals
+	if len(signals) == 0 {
+		panic("sigevents: cannot install goroutine dumps without signals")
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = events.DefaultLogger
+	}
+
+	handler := logger.Handler
+	if handler == nil {
+		handler = events.DefaultHandler
+	}
+
+	// The signal events are produced here instead of using events.SignalWith
+	// so their source is the caller of InstallDump, not this package.
+	var pc [1]uintptr
+	runtime.Callers(2, pc[:])
+	file, line := events.SourceForPC(pc[0])
+	source := fmt.Sprintf("%s:%d", file, line)
+
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, signals...)
+
+	exit := make(chan struct{})
+
+	go func() {
+		defer close(exit)
+
+		for sig := range sigchan {
+			handler.HandleEvent(&events.Event{
+				Message: sig.String(),
+				Source:  source,
+				Time:    time.Now(),
+				Args:    events.Args{{Name: "signal", Value: sig}},
+			})
+			LogGoroutines(logger, opts.Single)
+		}
+	}()
+
+	once := sync.Once{}
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigchan)
+			close(sigchan)
+			<-exit
+		})
+	}
+}
+
+// LogGoroutines emits the records of all goroutines of the program with
+// logger, either as one event per goroutine, or as a single event if single is
+// true.
+func LogGoroutines(logger *events.Logger, single bool) {
+	goroutines := Goroutines()
+
+	if single {
+		logger.Log("goroutine dump of %{count}d goroutines", len(goroutines), events.Args{
+			{Name: "goroutines", Value: goroutines},
+		})
+		return
+	}
+
+	logger.Log("dumping %{count}d goroutines", len(goroutines))
+
+	for i := range goroutines {
+		g := &goroutines[i]
+		args := events.Args{
+			{Name: "wait", Value: g.Wait},
+			{Name: "frames", Value: g.Frames},
+		}
+
+		if g.CreatedBy != nil {
+			args = append(args, events.Arg{Name: "created_by", Value: *g.CreatedBy})
+		}
+
+		logger.Log("goroutine %{id}d [%{state}s]", g.ID, g.State, args)
+	}
+}
@@ -0,0 +1,161 @@
+package sigevents
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+const goroutineDump = `goroutine 7 [running]:
+main.handle(0xc000010000?)
+	/src/main.go:42 +0xae
+created by main.serve in goroutine 1
+	/src/main.go:12 +0x4d4
+
+goroutine 1 [chan receive, 3 minutes, locked to thread]:
+testing.(*T).Run(0xc000102000, {0x554347?, 0xb}, 0x6d44d8)
+	/usr/local/go/src/testing/testing.go:2266 +0x4f2
+...additional frames elided...
+main.main()
+	/src/main.go:8 +0x9b
+
+goroutine 9 [running]:
+	goroutine running on other thread; stack unavailable
+`
+
+func TestParseGoroutines(t *testing.T) {
+	goroutines, err := ParseGoroutines([]byte(goroutineDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []Goroutine{
+		{
+			ID:        7,
+			State:     "running",
+			Frames:    []Frame{{"main.handle", "/src/main.go", 42}},
+			CreatedBy: &Frame{"main.serve", "/src/main.go", 12},
+		},
+		{
+			ID:     1,
+			State:  "chan receive",
+			Wait:   3 * time.Minute,
+			Locked: true,
+			Frames: []Frame{
+				{"testing.(*T).Run", "/usr/local/go/src/testing/testing.go", 2266},
+				{"main.main", "/src/main.go", 8},
+			},
+		},
+		{
+			ID:    9,
+			State: "running",
+		},
+	}
+
+	if !reflect.DeepEqual(goroutines, expect) {
+		t.Errorf("%+v", goroutines)
+	}
+}
+
+func TestParseGoroutinesError(t *testing.T) {
+	for _, dump := range []string{
+		"main.main()\n",
+		"goroutine X [running]:\n",
+		"goroutine 1 running\n",
+		"goroutine 1 [running]:\nmain.main()\n\t/src/main.go\n",
+	} {
+		if _, err := ParseGoroutines([]byte(dump)); err == nil {
+			t.Errorf("no error returned for %q", dump)
+		}
+	}
+}
+
+func TestGoroutines(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	go func() { <-block }()
+
+	var found bool
+	var blocked bool
+
+	for _, g := range Goroutines() {
+		for _, f := range g.Frames {
+			if f.Func == "github.com/segmentio/events/v2/sigevents.TestGoroutines" {
+				found = found || g.State == "running"
+			}
+		}
+		if g.CreatedBy != nil && g.CreatedBy.Func == "github.com/segmentio/events/v2/sigevents.TestGoroutines" {
+			blocked = true
+		}
+	}
+
+	if !found {
+		t.Error("the running goroutine was not found in the dump")
+	}
+	if !blocked {
+		t.Error("the goroutine created by the test was not found in the dump")
+	}
+}
+
+func TestInstallDumpWithoutSignals(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("InstallDump must panic when no signals are configured")
+		}
+	}()
+	InstallDump(DumpOptions{})
+}
+
+func TestInstallDump(t *testing.T) {
+	for _, single := range []bool{false, true} {
+		list := make(chan *events.Event, 1000)
+		uninstall := InstallDump(DumpOptions{
+			Signals: []os.Signal{syscall.SIGWINCH},
+			Logger: events.NewLogger(events.HandlerFunc(func(e *events.Event) {
+				list <- e.Clone()
+			})),
+			Single: single,
+		})
+
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(syscall.SIGWINCH)
+
+		next := func() *events.Event {
+			select {
+			case e := <-list:
+				return e
+			case <-time.After(time.Second):
+				t.Fatal("no events received within 1 second")
+				return nil
+			}
+		}
+
+		if e := next(); e.Message != "window changed" {
+			t.Error("the signal must be reported first:", e.Message)
+		} else if !strings.Contains(e.Source, "goroutines_test.go:") {
+			t.Error("the source of the signal event must be the caller of InstallDump:", e.Source)
+		}
+
+		e := next()
+
+		if single {
+			if v, _ := e.Args.Get("goroutines"); len(v.([]Goroutine)) == 0 {
+				t.Error("no goroutines reported in the dump:", e.Message)
+			}
+		} else {
+			v, _ := e.Args.Get("count")
+			for i := 0; i < v.(int); i++ {
+				if e := next(); e.Args[0].Name != "id" {
+					t.Error("bad goroutine event:", e.Message)
+				}
+			}
+		}
+
+		uninstall()
+	}
+}
@@ -0,0 +1,187 @@
+package sigevents
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/segmentio/events/v2"
+)
+
+// Target is implemented by the components which have their debug events
+// toggled by signals, *events.Logger satisfies the interface.
+//
+// Implementations must be safe to call concurrently with the code producing
+// events.
+type Target interface {
+	SetDebug(enable bool)
+}
+
+// TargetFunc is an adapter allowing the use of functions as targets.
+type TargetFunc func(enable bool)
+
+// SetDebug calls f(enable).
+func (f TargetFunc) SetDebug(enable bool) { f(enable) }
+
+// LevelTarget returns a target which sets the level of v to slog.LevelDebug
+// when debug events are enabled, and restores the level that v had before when
+// they are disabled.
+func LevelTarget(v *slog.LevelVar) Target {
+	var mutex sync.Mutex
+	var level slog.Level
+	var enabled bool
+
+	return TargetFunc(func(enable bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case enable && !enabled:
+			level = v.Level()
+			v.Set(slog.LevelDebug)
+		case !enable && enabled:
+			v.Set(level)
+		}
+
+		enabled = enable
+	})
+}
+
+// DebugFilterTarget returns a target which configures f with spec when debug
+// events are enabled, and clears the rules of f when they are disabled. The
+// function returns an error if spec is invalid.
+func DebugFilterTarget(f *events.DebugFilter, spec string) (Target, error) {
+	if _, err := events.NewDebugFilter(spec); err != nil {
+		return nil, err
+	}
+
+	return TargetFunc(func(enable bool) {
+		if enable {
+			f.Set(spec)
+		} else {
+			f.Set("")
+		}
+	}), nil
+}
+
+// Options configures the signal handlers installed by Install.
+type Options struct {
+	// Enable is the list of signals enabling debug events, when empty, SIGUSR1
+	// is used.
+	Enable []os.Signal
+
+	// Disable is the list of signals disabling debug events, when empty,
+	// SIGUSR2 is used.
+	Disable []os.Signal
+
+	// Targets is the list of components which have their debug events toggled.
+	// When empty, events.DefaultLogger is used, and if the EVENTS_DEBUG
+	// environment variable is set, events.DefaultDebugFilter is reloaded when
+	// debug events are enabled and cleared when they are disabled.
+	Targets []Target
+
+	// Logger produces the events confirming the state of debug events after
+	// each signal, when nil, events.DefaultLogger is used.
+	Logger *events.Logger
+}
+
+// Install installs signal handlers toggling the debug events of the targets
+// configured in opts. Each toggle produces an event confirming the new state.
+//
+// The returned function uninstalls the signal handlers, after it returned the
+// targets are not modified anymore.
+func Install(opts Options) (uninstall func()) {
+	enable := opts.Enable
+	if len(enable) == 0 {
+		enable = []os.Signal{syscall.SIGUSR1}
+	}
+
+	disable := opts.Disable
+	if len(disable) == 0 {
+		disable = []os.Signal{syscall.SIGUSR2}
+	}
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		targets = []Target{events.DefaultLogger, TargetFunc(reloadDefaultDebugFilter)}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = events.DefaultLogger
+	}
+
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, append(append([]os.Signal{}, enable...), disable...)...)
+
+	done := make(chan struct{})
+	exit := make(chan struct{})
+
+	go func() {
+		defer close(exit)
+
+		for {
+			select {
+			case sig := <-sigchan:
+				switch {
+				case contains(enable, sig):
+					toggle(logger, targets, sig, true)
+				case contains(disable, sig):
+					toggle(logger, targets, sig, false)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	once := sync.Once{}
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigchan)
+			close(done)
+			<-exit
+		})
+	}
+}
+
+func toggle(logger *events.Logger, targets []Target, sig os.Signal, enable bool) {
+	for _, target := range targets {
+		target.SetDebug(enable)
+	}
+
+	if enable {
+		logger.Log("debug events enabled after receiving %{signal}s", sig)
+	} else {
+		logger.Log("debug events disabled after receiving %{signal}s", sig)
+	}
+}
+
+func contains(signals []os.Signal, sig os.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+func reloadDefaultDebugFilter(enable bool) {
+	if _, ok := os.LookupEnv(events.DebugFilterEnv); !ok {
+		return
+	}
+
+	if !enable {
+		// Rules enabling debug events would otherwise take precedence over
+		// the EnableDebug field of the default logger.
+		events.DefaultDebugFilter.Set("")
+		return
+	}
+
+	if err := events.LoadDebugFilter(); err != nil {
+		events.Log("%{error}v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package sigevents
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+func TestInstall(t *testing.T) {
+	// Catch the signals in the test as well, so they don't terminate the
+	// program once the handlers are uninstalled.
+	sigchan := make(chan os.Signal, 10)
+	signal.Notify(sigchan, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(sigchan)
+
+	confirm := make(chan *events.Event, 10)
+	logger1 := events.NewLogger(nil)
+	logger2 := events.NewLogger(nil)
+	logger1.EnableDebug = false
+	logger2.EnableDebug = false
+
+	level := &slog.LevelVar{}
+	level.Set(slog.LevelWarn)
+
+	filter := &events.DebugFilter{}
+	filterTarget, err := DebugFilterTarget(filter, "github.com/acme/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uninstall := Install(Options{
+		Targets: []Target{logger1, logger2, LevelTarget(level), filterTarget},
+		Logger: events.NewLogger(events.HandlerFunc(func(e *events.Event) {
+			confirm <- e.Clone()
+		})),
+	})
+	defer uninstall()
+
+	p, _ := os.FindProcess(os.Getpid())
+
+	wait := func(message string) {
+		t.Helper()
+		select {
+		case e := <-confirm:
+			if e.Message != message {
+				t.Error("bad confirmation event:", e.Message)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no confirmation event received within 1 second")
+		}
+	}
+
+	p.Signal(syscall.SIGUSR1)
+	wait("debug events enabled after receiving user defined signal 1")
+
+	if !logger1.DebugEnabled() || !logger2.DebugEnabled() {
+		t.Error("debug events should be enabled after receiving SIGUSR1")
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Error("bad level after receiving SIGUSR1:", level.Level())
+	}
+	if filter.String() != "github.com/acme/*" {
+		t.Error("bad debug filter after receiving SIGUSR1:", filter)
+	}
+
+	p.Signal(syscall.SIGUSR2)
+	wait("debug events disabled after receiving user defined signal 2")
+
+	if logger1.DebugEnabled() || logger2.DebugEnabled() {
+		t.Error("debug events should not be enabled after receiving SIGUSR2")
+	}
+	if level.Level() != slog.LevelWarn {
+		t.Error("the level was not restored after receiving SIGUSR2:", level.Level())
+	}
+	if filter.String() != "" {
+		t.Error("bad debug filter after receiving SIGUSR2:", filter)
+	}
+
+	uninstall()
+	p.Signal(syscall.SIGUSR1)
+
+	// Wait for the three signals to be delivered.
+	for i := 0; i < 3; i++ {
+		<-sigchan
+	}
+
+	select {
+	case e := <-confirm:
+		t.Error("unexpected event after uninstalling the handlers:", e.Message)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if logger1.DebugEnabled() {
+		t.Error("debug events should not be toggled after uninstalling the handlers")
+	}
+}
+
+func TestDebugFilterTarget(t *testing.T) {
+	if _, err := DebugFilterTarget(&events.DebugFilter{}, "a=maybe"); err == nil {
+		t.Error("invalid specs must be rejected")
+	}
+}
+
+func TestReloadDefaultDebugFilter(t *testing.T) {
+	defer events.DefaultDebugFilter.Set(events.DefaultDebugFilter.String())
+	t.Setenv(events.DebugFilterEnv, "github.com/acme/*")
+
+	reloadDefaultDebugFilter(true)
+
+	if s := events.DefaultDebugFilter.String(); s != "github.com/acme/*" {
+		t.Error("the default debug filter was not reloaded:", s)
+	}
+
+	reloadDefaultDebugFilter(false)
+
+	if s := events.DefaultDebugFilter.String(); s != "" {
+		t.Error("the default debug filter was not cleared:", s)
+	}
+}
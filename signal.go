@@ -2,12 +2,12 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
 	"sync"
-	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -59,24 +59,25 @@ func SignalWith(handler Handler, sigchan <-chan os.Signal) <-chan os.Signal {
 
 // WithSignals returns a copy of the given context which may be canceled if any
 // of the given signals is received by the program.
+//
+// When a signal is received, the Err method of the context, and of contexts
+// derived from it, returns a *SignalError, which is also reported as the cause
+// of the cancellation by context.Cause.
 func WithSignals(ctx context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
-	done := make(chan struct{})
 	sigchan := make(chan os.Signal, 10)
 	sigrecv := Signal(sigchan)
 	signal.Notify(sigchan, signals...)
 
-	sig := &signalCtx{
-		parent: ctx,
-		done:   done,
-	}
+	sigctx, sigcancel := context.WithCancelCause(ctx)
+	sig := &signalCtx{Context: sigctx, done: make(chan struct{})}
 
 	go func() {
 		select {
 		case s := <-sigrecv:
-			sig.cancel(&SignalError{Signal: s})
-		case <-ctx.Done():
-			sig.cancel(ctx.Err())
+			sigcancel(&SignalError{Signal: s})
+		case <-sigctx.Done():
 		}
+		sig.close()
 		signal.Stop(sigchan)
 	}()
 
@@ -87,7 +88,8 @@ func WithSignals(ctx context.Context, signals ...os.Signal) (context.Context, co
 	cancel := func() {
 		once.Do(func() {
 			signal.Stop(sigchan)
-			sig.cancel(context.Canceled)
+			sigcancel(context.Canceled)
+			sig.close()
 			close(sigchan)
 		})
 	}
@@ -106,15 +108,61 @@ func (s *SignalError) Error() string {
 	return s.String()
 }
 
-type signalCtx struct {
-	parent context.Context
-	once   sync.Once
-	err    atomic.Value
-	done   chan struct{}
+// Is returns true if target is a *SignalError carrying the same signal, or no
+// signal at all, so errors wrapping a *SignalError can be tested with
+// errors.Is:
+//
+//	if errors.Is(err, &events.SignalError{Signal: syscall.SIGTERM}) {
+//		...
+//	}
+func (s *SignalError) Is(target error) bool {
+	t, ok := target.(*SignalError)
+	return ok && (t.Signal == nil || t.Signal == s.Signal)
+}
+
+// ExitCode returns the exit status conventionally associated with the signal,
+// which is 128 plus the signal number, or 1 if the signal has no number.
+func (s *SignalError) ExitCode() int {
+	if n, ok := s.Signal.(syscall.Signal); ok {
+		return 128 + int(n)
+	}
+	return 1
+}
+
+// ExitCode returns the exit status of a program terminating with err: 0 if err
+// is nil, the exit status associated with the signal if err is or wraps a
+// *SignalError, and 1 otherwise.
+//
+//	func main() {
+//		ctx, cancel := events.WithSignals(context.Background(), syscall.SIGTERM)
+//		defer cancel()
+//		...
+//		os.Exit(events.ExitCode(context.Cause(ctx)))
+//	}
+func ExitCode(err error) int {
+	var e *SignalError
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &e):
+		return e.ExitCode()
+	default:
+		return 1
+	}
 }
 
-func (s *signalCtx) Deadline() (time.Time, bool) {
-	return s.parent.Deadline()
+// signalCtx is a context canceled with a *SignalError as cause, its Err method
+// reports the cause so programs inspecting ctx.Err() see the signal.
+//
+// The context has its own done channel, which prevents the contexts derived
+// from it from attaching to the wrapped context directly: the context package
+// then propagates the cancellation with the value returned by the Err method,
+// so the signal is also reported by the Err method of the derived contexts.
+type signalCtx struct {
+	context.Context
+	once sync.Once
+	done chan struct{}
 }
 
 func (s *signalCtx) Done() <-chan struct{} {
@@ -122,26 +170,31 @@ func (s *signalCtx) Done() <-chan struct{} {
 }
 
 func (s *signalCtx) Err() error {
-	err, _ := s.err.Load().(error)
+	select {
+	case <-s.done:
+	default:
+		return nil
+	}
+	err := s.Context.Err()
+	if cause, ok := context.Cause(s.Context).(*SignalError); ok {
+		return cause
+	}
 	return err
 }
 
-func (c *signalCtx) Value(key interface{}) interface{} {
-	return c.parent.Value(key)
+// close closes the done channel, it must be called after the wrapped context
+// was canceled.
+func (s *signalCtx) close() {
+	s.once.Do(func() { close(s.done) })
 }
 
-func (s *signalCtx) cancel(err error) {
-	s.once.Do(func() {
-		s.err.Store(err)
-		close(s.done)
-	})
-}
-
-// IsSignal returns true if the given error is a *SignalError that was
-// generated upon receipt of one of the given signals. If no signal is
+// IsSignal returns true if the given error is, or wraps, a *SignalError that
+// was generated upon receipt of one of the given signals. If no signal is
 // passed, the function only tests for err to be of type *SignalError.
 func IsSignal(err error, signals ...os.Signal) bool {
-	if e, ok := err.(*SignalError); ok {
+	var e *SignalError
+
+	if errors.As(err, &e) {
 		if len(signals) == 0 {
 			return true
 		}
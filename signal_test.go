@@ -2,6 +2,8 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
 	"syscall"
@@ -85,6 +87,10 @@ func TestWithSignals(t *testing.T) {
 			return
 		}
 
+		if cause := context.Cause(ctx); cause != ctx.Err() {
+			t.Error("the signal must be reported as the cause of the cancellation:", cause)
+		}
+
 		err := ctx.Err()
 
 		switch e := err.(type) {
@@ -97,6 +103,36 @@ func TestWithSignals(t *testing.T) {
 		}
 	})
 
+	t.Run("report the signal in derived contexts", func(t *testing.T) {
+		ctx, cancel := WithSignals(context.Background(), os.Interrupt)
+		defer cancel()
+
+		child, cancelChild := context.WithCancel(ctx)
+		defer cancelChild()
+
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(os.Interrupt)
+
+		select {
+		case <-child.Done():
+		case <-time.After(time.Second):
+			t.Error("no signals received within 1 second")
+			return
+		}
+
+		if err := child.Err(); !IsSignal(err, os.Interrupt) {
+			t.Error("bad error returned by the derived context:", err)
+		}
+
+		if cause := context.Cause(child); !IsSignal(cause, os.Interrupt) {
+			t.Error("bad cause reported by the derived context:", cause)
+		}
+
+		if code := ExitCode(child.Err()); code != 128+int(syscall.SIGINT) {
+			t.Error("bad exit code:", code)
+		}
+	})
+
 	t.Run("report cancellation of the parent context", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -116,6 +152,10 @@ func TestWithSignals(t *testing.T) {
 		if err := sig.Err(); err != context.Canceled {
 			t.Error("the parent error wasn't reported:", err)
 		}
+
+		if cause := context.Cause(sig); cause != context.Canceled {
+			t.Error("the parent cause wasn't reported:", cause)
+		}
 	})
 }
 
@@ -136,3 +176,46 @@ func TestIsInterruption(t *testing.T) {
 		t.Error("SIGTERM was mistakenly recognized as a interruption error")
 	}
 }
+
+func TestSignalErrorWrapped(t *testing.T) {
+	err := fmt.Errorf("shutting down: %w", &SignalError{Signal: syscall.SIGTERM})
+
+	if !IsTermination(err) {
+		t.Error("wrapped SIGTERM wasn't recognized as a termination error")
+	}
+	if IsInterruption(err) {
+		t.Error("wrapped SIGTERM was mistakenly recognized as a interruption error")
+	}
+	if !IsSignal(err) {
+		t.Error("wrapped signal error wasn't recognized")
+	}
+
+	if !errors.Is(err, &SignalError{Signal: syscall.SIGTERM}) {
+		t.Error("errors.Is must match signal errors carrying the same signal")
+	}
+	if !errors.Is(err, &SignalError{}) {
+		t.Error("errors.Is must match signal errors carrying no signal")
+	}
+	if errors.Is(err, &SignalError{Signal: syscall.SIGINT}) {
+		t.Error("errors.Is must not match signal errors carrying other signals")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("oops"), 1},
+		{context.Canceled, 1},
+		{&SignalError{Signal: syscall.SIGTERM}, 128 + int(syscall.SIGTERM)},
+		{fmt.Errorf("%w", &SignalError{Signal: syscall.SIGINT}), 128 + int(syscall.SIGINT)},
+	}
+
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("%v: %d != %d", test.err, code, test.code)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+// Package slogevents provides adapters between the events package and the
+// log/slog package of the standard library.
+//
+// The SlogHandler type lets programs emit slog records through existing event
+// handlers, so new code using log/slog produces the same output as code using
+// the events package. The Handler type goes the other way, publishing events
+// produced by event loggers to a slog handler.
+package slogevents
@@ -0,0 +1,120 @@
+package slogevents
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/segmentio/events/v2"
+)
+
+// Handler is an event handler which converts the events it receives to slog
+// records and passes them to a slog handler.
+//
+// The level of records is the severity of the events (see events.Event.Severity)
+// translated to the matching slog level.
+//
+// Event arguments become record attributes, and the event source is reported
+// as a "source" attribute.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type Handler struct {
+	// Handler is the slog handler receiving records. When nil, records are
+	// sent to the handler of the default slog logger.
+	Handler slog.Handler
+}
+
+// NewHandler creates a new event handler which sends records to handler.
+func NewHandler(handler slog.Handler) *Handler {
+	return &Handler{Handler: handler}
+}
+
+// HandleEvent satisfies the events.Handler interface.
+func (h *Handler) HandleEvent(e *events.Event) {
+	handler := h.Handler
+	if handler == nil {
+		handler = slog.Default().Handler()
+	}
+
+	ctx := context.Background()
+	level := levelOf(e)
+
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+
+	r := slog.NewRecord(e.Time, level, e.Message, 0)
+
+	if len(e.Source) != 0 {
+		r.AddAttrs(slog.String(slog.SourceKey, e.Source))
+	}
+
+	for _, a := range e.Args {
+		r.AddAttrs(slog.Attr{Key: a.Name, Value: slogValue(a.Value)})
+	}
+
+	handler.Handle(ctx, r)
+}
+
+// slogValue converts v to a slog value, events.Value are converted to the slog
+// value of the same kind, events.Valuer to slog.LogValuer and events.Group to
+// slog groups.
+func slogValue(v interface{}) slog.Value {
+	switch x := v.(type) {
+	case events.Value:
+		return slogValueOf(&x)
+	case *events.Value:
+		return slogValueOf(x)
+	case events.Valuer:
+		return slog.AnyValue(logValuer{x})
+	case events.Group:
+		attrs := make([]slog.Attr, len(x))
+		for i, a := range x {
+			attrs[i] = slog.Attr{Key: a.Name, Value: slogValue(a.Value)}
+		}
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// logValuer adapts an events.Valuer to the slog.LogValuer interface, so it is
+// resolved by slog handlers.
+type logValuer struct{ events.Valuer }
+
+func (v logValuer) LogValue() slog.Value {
+	return slogValue(v.EventValue())
+}
+
+func slogValueOf(v *events.Value) slog.Value {
+	switch v.Kind() {
+	case events.KindBool:
+		return slog.BoolValue(v.Bool())
+	case events.KindDuration:
+		return slog.DurationValue(v.Duration())
+	case events.KindFloat64:
+		return slog.Float64Value(v.Float64())
+	case events.KindInt64:
+		return slog.Int64Value(v.Int64())
+	case events.KindString:
+		return slog.StringValue(v.String())
+	case events.KindTime:
+		return slog.TimeValue(v.Time())
+	case events.KindUint64:
+		return slog.Uint64Value(v.Uint64())
+	default:
+		return slog.AnyValue(v.Any())
+	}
+}
+
+func levelOf(e *events.Event) slog.Level {
+	switch e.Severity() {
+	case events.LevelDebug:
+		return slog.LevelDebug
+	case events.LevelWarn:
+		return slog.LevelWarn
+	case events.LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,124 @@
+package slogevents
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/segmentio/events/v2"
+)
+
+func TestHandler(t *testing.T) {
+	luke := events.String("Luke")
+
+	tests := []struct {
+		name   string
+		event  events.Event
+		output string
+	}{
+		{
+			name: "info",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Source:  "github.com/segmentio/events/slogevents/handler_test.go:18",
+				Args:    events.Args{{Name: "name", Value: "Luke"}, {Name: "from", Value: "Han"}},
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=INFO msg="Hello Luke!" source=github.com/segmentio/events/slogevents/handler_test.go:18 name=Luke from=Han` + "\n",
+		},
+		{
+			name: "debug",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Debug:   true,
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=DEBUG msg="Hello Luke!"` + "\n",
+		},
+		{
+			name: "error",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Args:    events.Args{{Name: "error", Value: io.EOF}},
+				Debug:   true,
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=ERROR msg="Hello Luke!" error=EOF` + "\n",
+		},
+		{
+			name: "values",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Args: events.Args{
+					{Name: "count", Value: events.Int(42)},
+					{Name: "elapsed", Value: events.Duration(time.Second)},
+					{Name: "name", Value: &luke},
+				},
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=INFO msg="Hello Luke!" count=42 elapsed=1s name=Luke` + "\n",
+		},
+		{
+			name: "lazy",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Args:    events.Args{{Name: "name", Value: events.Lazy(func() interface{} { return &luke })}},
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=INFO msg="Hello Luke!" name=Luke` + "\n",
+		},
+		{
+			name: "group",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Args:    events.Args{{Name: "db", Value: events.Group{{Name: "id", Value: 42}, {Name: "table", Value: "users"}}}},
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=INFO msg="Hello Luke!" db.id=42 db.table=users` + "\n",
+		},
+		{
+			name: "warn",
+			event: events.Event{
+				Message: "Hello Luke!",
+				Level:   events.LevelWarn,
+			},
+			output: `time=2017-01-01T23:42:00.123Z level=WARN msg="Hello Luke!"` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			h := NewHandler(slog.NewTextHandler(b, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+			}))
+
+			test.event.Time = time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC)
+			h.HandleEvent(&test.event)
+
+			if s := b.String(); s != test.output {
+				t.Error(s)
+			}
+		})
+	}
+}
+
+func TestHandlerDisabled(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler(slog.NewTextHandler(b, nil))
+	h.HandleEvent(&events.Event{Message: "Hello Luke!", Debug: true})
+
+	if b.Len() != 0 {
+		t.Error("debug records should be discarded:", b.String())
+	}
+}
+
+func BenchmarkHandler(b *testing.B) {
+	h := NewHandler(slog.NewTextHandler(io.Discard, nil))
+	e := &events.Event{
+		Message: "Hello Luke!",
+		Source:  "github.com/segmentio/events/slogevents/handler_test.go:18",
+		Args:    events.Args{{Name: "name", Value: "Luke"}, {Name: "from", Value: "Han"}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+	}
+
+	for i := 0; i != b.N; i++ {
+		h.HandleEvent(e)
+	}
+}
@@ -0,0 +1,172 @@
+package slogevents
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/segmentio/events/v2"
+)
+
+// SlogHandler is an implementation of the slog.Handler interface which converts
+// the records it receives to events and passes them to an event handler.
+//
+// Record attributes become event arguments. Attributes nested within groups
+// are named after the path of the groups they belong to, joined with dots (for
+// example "request.method"). The arguments carried by the context passed to
+// Handle (see events.ContextWithArgs) are injected before the record attributes.
+//
+// The program counter of records is translated to the event source, and their
+// level to the event level. Records with a level lower or equal to
+// slog.LevelDebug produce debug events.
+//
+// It is safe to use a handler concurrently from multiple goroutines.
+type SlogHandler struct {
+	// Handler is the event handler receiving events from the slog handler.
+	// When nil, events are sent to events.DefaultHandler.
+	Handler events.Handler
+
+	// Level is the minimum level of records converted to events. When nil,
+	// records of all levels are accepted.
+	Level slog.Leveler
+
+	args   events.Args // arguments added by WithAttrs
+	prefix string      // group prefix set by WithGroup
+}
+
+// NewSlogHandler creates a new slog handler which sends events to handler.
+func NewSlogHandler(handler events.Handler) *SlogHandler {
+	return &SlogHandler{Handler: handler}
+}
+
+// Enabled satisfies the slog.Handler interface.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.Level == nil || level >= h.Level.Level()
+}
+
+// Handle satisfies the slog.Handler interface.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := h.Handler
+	if handler == nil {
+		handler = events.DefaultHandler
+	}
+
+	s := slogPool.Get().(*slogState)
+	s.e.Message = r.Message
+	s.e.Time = r.Time
+	s.e.Debug = r.Level <= slog.LevelDebug
+	s.e.Level = eventLevel(r.Level)
+	s.e.Args = append(s.e.Args, h.args...)
+	s.e.Args = events.AppendArgsFromContext(s.e.Args, ctx)
+
+	if r.PC != 0 {
+		file, line := events.SourceForPC(r.PC)
+		s.src = append(s.src, file...)
+		s.src = append(s.src, ':')
+		s.src = strconv.AppendUint(s.src, uint64(line), 10)
+		s.e.Source = string(s.src)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		s.e.Args = appendAttr(s.e.Args, h.prefix, a)
+		return true
+	})
+
+	handler.HandleEvent(&s.e)
+
+	// don't hold pointers to let the garbage collector free the objects
+	for i := range s.e.Args {
+		s.e.Args[i] = events.Arg{}
+	}
+
+	s.e = events.Event{Args: s.e.Args[:0]}
+	s.src = s.src[:0]
+	slogPool.Put(s)
+	return nil
+}
+
+func eventLevel(level slog.Level) events.Level {
+	switch {
+	case level >= slog.LevelError:
+		return events.LevelError
+	case level >= slog.LevelWarn:
+		return events.LevelWarn
+	case level >= slog.LevelInfo:
+		return events.LevelInfo
+	default:
+		return events.LevelDebug
+	}
+}
+
+// WithAttrs satisfies the slog.Handler interface.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	c := h.clone()
+	c.args = make(events.Args, 0, len(h.args)+len(attrs))
+	c.args = append(c.args, h.args...)
+
+	for _, a := range attrs {
+		c.args = appendAttr(c.args, h.prefix, a)
+	}
+
+	return c
+}
+
+// WithGroup satisfies the slog.Handler interface.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	c := h.clone()
+	c.prefix = h.prefix + name + "."
+	return c
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	return &SlogHandler{
+		Handler: h.Handler,
+		Level:   h.Level,
+		args:    h.args,
+		prefix:  h.prefix,
+	}
+}
+
+func appendAttr(args events.Args, prefix string, a slog.Attr) events.Args {
+	v := a.Value.Resolve()
+
+	if v.Kind() == slog.KindGroup {
+		// Groups with no names have their attributes inlined in the parent.
+		if len(a.Key) != 0 {
+			prefix += a.Key + "."
+		}
+		for _, a := range v.Group() {
+			args = appendAttr(args, prefix, a)
+		}
+		return args
+	}
+
+	if len(a.Key) == 0 && v.Any() == nil {
+		return args
+	}
+
+	return append(args, events.Arg{Name: prefix + a.Key, Value: v.Any()})
+}
+
+// slogState is used to build events produced by SlogHandler instances.
+type slogState struct {
+	e   events.Event
+	src []byte
+}
+
+var slogPool = sync.Pool{
+	New: func() interface{} {
+		return &slogState{
+			e:   events.Event{Args: make(events.Args, 0, 8)},
+			src: make([]byte, 0, 256),
+		}
+	},
+}
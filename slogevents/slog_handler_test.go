@@ -0,0 +1,102 @@
+package slogevents
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/events/v2"
+	"github.com/segmentio/events/v2/eventstest"
+)
+
+func TestSlogHandler(t *testing.T) {
+	h := &eventstest.Handler{}
+	logger := slog.New(NewSlogHandler(h))
+
+	logger.Info("Hello Luke!", "name", "Luke", slog.Group("from", "name", "Han"))
+	logger.Debug("Hello Leia!", "name", "Leia")
+	logger.With("answer", 42).WithGroup("ship").Warn("Hello Chewie!", "name", "Millennium Falcon")
+	logger.Error("something went wrong", "error", io.EOF)
+
+	h.AssertEvents(t,
+		events.Event{
+			Message: "Hello Luke!",
+			Args:    events.Args{{Name: "name", Value: "Luke"}, {Name: "from.name", Value: "Han"}},
+			Level:   events.LevelInfo,
+		},
+		events.Event{
+			Message: "Hello Leia!",
+			Args:    events.Args{{Name: "name", Value: "Leia"}},
+			Debug:   true,
+			Level:   events.LevelDebug,
+		},
+		events.Event{
+			Message: "Hello Chewie!",
+			Args:    events.Args{{Name: "answer", Value: int64(42)}, {Name: "ship.name", Value: "Millennium Falcon"}},
+			Level:   events.LevelWarn,
+		},
+		events.Event{
+			Message: "something went wrong",
+			Args:    events.Args{{Name: "error", Value: io.EOF}},
+			Level:   events.LevelError,
+		},
+	)
+}
+
+func TestSlogHandlerContext(t *testing.T) {
+	h := &eventstest.Handler{}
+	logger := slog.New(NewSlogHandler(h)).With("answer", 42)
+
+	ctx := events.ContextWithArgs(context.Background(), events.Args{{Name: "method", Value: "GET"}})
+	logger.InfoContext(ctx, "Hello Luke!", "name", "Luke")
+
+	h.AssertEvents(t, events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "answer", Value: int64(42)},
+			{Name: "method", Value: "GET"},
+			{Name: "name", Value: "Luke"},
+		},
+		Level: events.LevelInfo,
+	})
+}
+
+func TestSlogHandlerSource(t *testing.T) {
+	var source string
+
+	logger := slog.New(NewSlogHandler(events.HandlerFunc(func(e *events.Event) {
+		source = e.Source
+	})))
+	logger.Info("Hello World!")
+
+	if !strings.Contains(source, "slog_handler_test.go:") {
+		t.Error("bad source:", source)
+	}
+}
+
+func TestSlogHandlerLevel(t *testing.T) {
+	h := &eventstest.Handler{}
+	s := NewSlogHandler(h)
+	s.Level = slog.LevelInfo
+
+	if s.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug records should not be enabled")
+	}
+
+	if !s.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info records should be enabled")
+	}
+
+	slog.New(s).Debug("Hello World!")
+	h.AssertEvents(t)
+}
+
+func BenchmarkSlogHandler(b *testing.B) {
+	logger := slog.New(NewSlogHandler(events.Discard))
+
+	for i := 0; i != b.N; i++ {
+		logger.Info("Hello World!", "name", "Luke", "from", "Han")
+	}
+}
@@ -1,8 +1,10 @@
 package text
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"runtime"
 	"sync"
 	"time"
 
@@ -28,6 +30,7 @@ type Handler struct {
 	TimeFormat   string         // format used for the event's time
 	TimeLocation *time.Location // location to output the event time in
 	EnableArgs   bool           // output detailes of each args in the events
+	EnableLevel  bool           // output the severity level of the events
 
 	// synchronizes writes to the output
 	mutex sync.Mutex
@@ -58,6 +61,11 @@ func (h *Handler) HandleEvent(e *events.Event) {
 		buf.b = append(buf.b, " - "...)
 	}
 
+	if h.EnableLevel {
+		buf.b = append(buf.b, e.Severity().String()...)
+		buf.b = append(buf.b, " - "...)
+	}
+
 	if len(e.Source) != 0 {
 		buf.b = append(buf.b, e.Source...)
 		buf.b = append(buf.b, " - "...)
@@ -67,25 +75,28 @@ func (h *Handler) HandleEvent(e *events.Event) {
 	buf.b = append(buf.b, '\n')
 
 	if h.EnableArgs {
-		hasError := false
-
-		for _, a := range e.Args {
-			if _, ok := a.Value.(error); ok {
-				hasError = true
-			} else {
-				buf.b = append(buf.b, '\t')
-				buf.b = append(buf.b, a.Name...)
-				buf.b = append(buf.b, ':', ' ')
-				fmt.Fprintf(buf, "%v\n", a.Value)
+		buf.appendArgs(e.Args, nil)
+
+		if len(buf.errors) != 0 {
+			fmt.Fprint(buf, "\terrors:\n")
+
+			for i, err := range buf.errors {
+				fmt.Fprintf(buf, "\t\t- %+v\n", err)
+				buf.errors[i] = nil
 			}
+
+			buf.errors = buf.errors[:0]
 		}
 
-		if hasError {
-			fmt.Fprint(buf, "\terrors:\n")
+		if len(e.Stack) != 0 {
+			fmt.Fprint(buf, "\tstack:\n")
+			frames := runtime.CallersFrames(e.Stack)
 
-			for _, a := range e.Args {
-				if err, ok := a.Value.(error); ok {
-					fmt.Fprintf(buf, "\t\t- %+v\n", err)
+			for {
+				f, more := frames.Next()
+				fmt.Fprintf(buf, "\t\t- %s\n\t\t\t%s:%d\n", f.Function, f.File, f.Line)
+				if !more {
+					break
 				}
 			}
 		}
@@ -97,10 +108,76 @@ func (h *Handler) HandleEvent(e *events.Event) {
 	bufferPool.Put(buf)
 }
 
+// Flush satisfies the events.Flusher interface, it flushes the handler's output
+// if it has a Flush method (like *bufio.Writer).
+func (h *Handler) Flush(ctx context.Context) error {
+	f, ok := h.Output.(interface{ Flush() error })
+	if !ok {
+		return nil
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return f.Flush()
+}
+
 // This buffer type is used as an optimization, it's faster than the standard
 // bytes.Buffer because it doesn't expose such a rich API.
 type buffer struct {
-	b []byte
+	b      []byte
+	errors []error
+}
+
+// appendArgs writes args to buf, one per line, errors are collected to be
+// written in a separate section. The arguments of events.Group values are
+// written with names prefixed by the path of the groups they belong to.
+//
+// Errors produced by valuers are written like other arguments, since valuers
+// are not inspected when determining the severity of events.
+func (buf *buffer) appendArgs(args events.Args, prefix []byte) {
+	for _, a := range args {
+		_, lazy := a.Value.(events.Valuer)
+		v := events.Resolve(a.Value)
+
+		// Errors held by values are reported like errors passed directly.
+		if err, ok := valueError(v); ok {
+			v = err
+		}
+
+		switch x := v.(type) {
+		case error:
+			if !lazy {
+				buf.errors = append(buf.errors, x)
+				continue
+			}
+		case events.Group:
+			buf.appendArgs(events.Args(x), append(append(prefix, a.Name...), '.'))
+			continue
+		}
+
+		buf.b = append(buf.b, '\t')
+		buf.b = append(buf.b, prefix...)
+		buf.b = append(buf.b, a.Name...)
+		buf.b = append(buf.b, ':', ' ')
+		fmt.Fprintf(buf, "%v\n", v)
+	}
+}
+
+// valueError returns the error held by v if it is an events.Value, or a
+// pointer to one, of kind events.KindAny holding an error.
+func valueError(v interface{}) (error, bool) {
+	switch x := v.(type) {
+	case events.Value:
+		if x.Kind() == events.KindAny {
+			err, ok := x.Any().(error)
+			return err, ok
+		}
+	case *events.Value:
+		if x != nil && x.Kind() == events.KindAny {
+			err, ok := x.Any().(error)
+			return err, ok
+		}
+	}
+	return nil, false
 }
 
 func (buf *buffer) Write(b []byte) (n int, err error) {
@@ -110,5 +187,5 @@ func (buf *buffer) Write(b []byte) (n int, err error) {
 }
 
 var bufferPool = sync.Pool{
-	New: func() interface{} { return &buffer{make([]byte, 0, 4096)} },
+	New: func() interface{} { return &buffer{b: make([]byte, 0, 4096)} },
 }
@@ -1,9 +1,13 @@
 package text
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
+	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,6 +18,7 @@ func TestHandler(t *testing.T) {
 	tests := []struct {
 		name   string
 		args   bool
+		level  bool
 		output string
 	}{
 		{
@@ -31,6 +36,11 @@ func TestHandler(t *testing.T) {
 			args:   false,
 			output: "==> 2017-01-01 23:42:00.123 - github.com/segmentio/events/text/handler_test.go:18 - Hello Luke!\n",
 		},
+		{
+			name:   "EnableLevel:true",
+			level:  true,
+			output: "==> 2017-01-01 23:42:00.123 - ERROR - github.com/segmentio/events/text/handler_test.go:18 - Hello Luke!\n",
+		},
 	}
 
 	for _, test := range tests {
@@ -38,6 +48,7 @@ func TestHandler(t *testing.T) {
 			b := &bytes.Buffer{}
 			h := NewHandler("==> ", b)
 			h.EnableArgs = test.args
+			h.EnableLevel = test.level
 
 			h.HandleEvent(&events.Event{
 				Message: "Hello Luke!",
@@ -54,6 +65,125 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandlerValues(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler("==> ", b)
+	h.EnableArgs = true
+	n := events.Int(42)
+
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "name", Value: events.String("Luke")}, {Name: "answer", Value: &n}, {Name: "elapsed", Value: events.Duration(time.Second)}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+	})
+
+	if s := b.String(); s != `==> 2017-01-01 23:42:00.123 - Hello Luke!
+	name: Luke
+	answer: 42
+	elapsed: 1s
+` {
+		t.Error(s)
+	}
+}
+
+func TestHandlerValueError(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler("==> ", b)
+	h.EnableArgs = true
+	h.EnableLevel = true
+	v := events.Any(io.EOF)
+
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "error", Value: events.Any(io.ErrUnexpectedEOF)}, {Name: "cause", Value: &v}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+	})
+
+	if s := b.String(); s != `==> 2017-01-01 23:42:00.123 - ERROR - Hello Luke!
+	errors:
+		- unexpected EOF
+		- EOF
+` {
+		t.Error(s)
+	}
+}
+
+func TestHandlerLazy(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler("==> ", b)
+	h.EnableArgs = true
+
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "name", Value: events.Lazy(func() interface{} { return "Luke" })},
+			{Name: "error", Value: events.Lazy(func() interface{} { return io.EOF })},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+	})
+
+	if s := b.String(); s != `==> 2017-01-01 23:42:00.123 - Hello Luke!
+	name: Luke
+	error: EOF
+` {
+		t.Error(s)
+	}
+}
+
+func TestHandlerGroup(t *testing.T) {
+	b := &bytes.Buffer{}
+	h := NewHandler("==> ", b)
+	h.EnableArgs = true
+
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args: events.Args{
+			{Name: "id", Value: 1},
+			{Name: "db", Value: events.Group{
+				{Name: "id", Value: 2},
+				{Name: "query", Value: events.Group{{Name: "table", Value: "users"}}},
+				{Name: "host", Value: "localhost"},
+			}},
+		},
+		Time: time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+	})
+
+	if s := b.String(); s != `==> 2017-01-01 23:42:00.123 - Hello Luke!
+	id: 1
+	db.id: 2
+	db.query.table: users
+	db.host: localhost
+` {
+		t.Error(s)
+	}
+}
+
+func TestHandlerEventStack(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	b := &bytes.Buffer{}
+	h := NewHandler("==> ", b)
+	h.EnableArgs = true
+
+	h.HandleEvent(&events.Event{
+		Message: "Hello Luke!",
+		Args:    events.Args{{Name: "error", Value: io.EOF}},
+		Time:    time.Date(2017, 1, 1, 23, 42, 0, 123000000, time.UTC),
+		Stack:   pcs[:],
+	})
+
+	if s := b.String(); s != `==> 2017-01-01 23:42:00.123 - Hello Luke!
+	errors:
+		- EOF
+	stack:
+		- github.com/segmentio/events/v2/text.TestHandlerEventStack
+			`+frame.File+":"+strconv.Itoa(frame.Line)+"\n" {
+		t.Error(s)
+	}
+}
+
 func BenchmarkHandler(b *testing.B) {
 	h := NewHandler("", ioutil.Discard)
 	e := &events.Event{
@@ -68,3 +198,23 @@ func BenchmarkHandler(b *testing.B) {
 		h.HandleEvent(e)
 	}
 }
+
+func TestHandlerFlush(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := bufio.NewWriter(b)
+	h := NewHandler("", w)
+	h.TimeFormat = ""
+	h.HandleEvent(&events.Event{Message: "Hello Luke!"})
+
+	if b.Len() != 0 {
+		t.Fatal("the output should be buffered")
+	}
+
+	if err := events.FlushHandler(context.Background(), h); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := b.String(); s != "Hello Luke!\n" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,408 @@
+package events
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Kind is the kind of a Value.
+type Kind int
+
+const (
+	// KindAny is the kind of values holding arbitrary Go values.
+	KindAny Kind = iota
+	KindBool
+	KindDuration
+	KindFloat64
+	KindInt64
+	KindString
+	KindTime
+	KindUint64
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindAny:
+		return "Any"
+	case KindBool:
+		return "Bool"
+	case KindDuration:
+		return "Duration"
+	case KindFloat64:
+		return "Float64"
+	case KindInt64:
+		return "Int64"
+	case KindString:
+		return "String"
+	case KindTime:
+		return "Time"
+	case KindUint64:
+		return "Uint64"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Value is a representation of event argument values which holds common types
+// without boxing them in interfaces, similarly to slog.Value.
+//
+// Values and pointers to values can be used as event arguments. Like any other
+// Go value, storing a Value in an argument boxes it in an interface, which
+// allocates. Programs that need to avoid allocations when logging can store
+// pointers to values held in memory that they reuse (like a pooled object)
+// instead, which doesn't cause allocations.
+//
+// Values implement fmt.Formatter, they are formatted like the Go values they
+// hold, and the handlers of the events sub-packages encode them natively.
+// Event.Clone converts values to the Go values they hold (for example Int
+// values become int values), so handlers retaining cloned events don't have to
+// deal with them. Values holding errors are detected like errors passed
+// directly as event arguments.
+//
+// The zero-value is a KindAny value holding nil.
+type Value struct {
+	// num holds the numeric values, or the length of strings.
+	num uint64
+
+	// any holds the kind of numeric values, a pointer to the bytes of
+	// strings, the location of times, or the value itself for other kinds.
+	// The kind of a Value is determined by the type of any.
+	any interface{}
+}
+
+type (
+	// kind is the type stored in the any field of numeric values, it is
+	// distinct from Kind so values of type Kind can be held by KindAny values.
+	kind Kind
+
+	// stringptr is the type stored in the any field of strings.
+	stringptr *byte
+
+	// timeLocation is the type stored in the any field of times represented
+	// in nanoseconds since the epoch.
+	timeLocation *time.Location
+
+	// timeTime is the type stored in the any field of other times.
+	timeTime time.Time
+)
+
+// kindInt is the kind of values created by Int, which are of kind KindInt64
+// but hold int values.
+const kindInt kind = -1
+
+// Int returns a Value holding an int. Its kind is KindInt64, but the Any
+// method returns an int.
+func Int(v int) Value {
+	return Value{num: uint64(v), any: kindInt}
+}
+
+// Int64 returns a Value holding an int64.
+func Int64(v int64) Value {
+	return Value{num: uint64(v), any: kind(KindInt64)}
+}
+
+// Uint64 returns a Value holding a uint64.
+func Uint64(v uint64) Value {
+	return Value{num: v, any: kind(KindUint64)}
+}
+
+// Float64 returns a Value holding a float64.
+func Float64(v float64) Value {
+	return Value{num: math.Float64bits(v), any: kind(KindFloat64)}
+}
+
+// String returns a Value holding a string.
+func String(v string) Value {
+	return Value{num: uint64(len(v)), any: stringptr(unsafe.StringData(v))}
+}
+
+// Bool returns a Value holding a bool.
+func Bool(v bool) Value {
+	var n uint64
+	if v {
+		n = 1
+	}
+	return Value{num: n, any: kind(KindBool)}
+}
+
+// Duration returns a Value holding a time.Duration.
+func Duration(v time.Duration) Value {
+	return Value{num: uint64(v), any: kind(KindDuration)}
+}
+
+// Time returns a Value holding a time.Time. The monotonic clock reading of the
+// time is discarded.
+func Time(v time.Time) Value {
+	// Times that can be represented in nanoseconds since the epoch are stored
+	// without allocating, the location pointer fits in the interface.
+	if y := v.Year(); !v.IsZero() && y > 1677 && y < 2262 {
+		return Value{num: uint64(v.UnixNano()), any: timeLocation(v.Location())}
+	}
+	return Value{any: timeTime(v.Round(0))}
+}
+
+// Any returns a Value holding v. Values of the types that Value holds natively
+// get the matching kind, other values are of kind KindAny.
+func Any(v interface{}) Value {
+	switch x := v.(type) {
+	case Value:
+		return x
+	case *Value:
+		return *x
+	case bool:
+		return Bool(x)
+	case int:
+		return Int(x)
+	case int64:
+		return Int64(x)
+	case uint64:
+		return Uint64(x)
+	case float64:
+		return Float64(x)
+	case string:
+		return String(x)
+	case time.Duration:
+		return Duration(x)
+	case time.Time:
+		return Time(x)
+	default:
+		return Value{any: v}
+	}
+}
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind {
+	switch x := v.any.(type) {
+	case kind:
+		if x == kindInt {
+			return KindInt64
+		}
+		return Kind(x)
+	case stringptr:
+		return KindString
+	case timeLocation, timeTime:
+		return KindTime
+	default:
+		return KindAny
+	}
+}
+
+// Any returns the Go value held by v.
+func (v Value) Any() interface{} {
+	switch v.Kind() {
+	case KindBool:
+		return v.Bool()
+	case KindDuration:
+		return v.Duration()
+	case KindFloat64:
+		return v.Float64()
+	case KindInt64:
+		if v.any == kindInt {
+			return int(v.num)
+		}
+		return v.Int64()
+	case KindString:
+		return v.str()
+	case KindTime:
+		return v.Time()
+	case KindUint64:
+		return v.num
+	default:
+		return v.any
+	}
+}
+
+// str returns the string held by v, which must be of kind KindString.
+func (v Value) str() string {
+	p, _ := v.any.(stringptr)
+	return unsafe.String(p, v.num)
+}
+
+// Bool returns the bool held by v, which must be of kind KindBool.
+func (v Value) Bool() bool {
+	v.mustBe(KindBool)
+	return v.num != 0
+}
+
+// Duration returns the time.Duration held by v, which must be of kind
+// KindDuration.
+func (v Value) Duration() time.Duration {
+	v.mustBe(KindDuration)
+	return time.Duration(v.num)
+}
+
+// Float64 returns the float64 held by v, which must be of kind KindFloat64.
+func (v Value) Float64() float64 {
+	v.mustBe(KindFloat64)
+	return math.Float64frombits(v.num)
+}
+
+// Int64 returns the int64 held by v, which must be of kind KindInt64.
+func (v Value) Int64() int64 {
+	v.mustBe(KindInt64)
+	return int64(v.num)
+}
+
+// Uint64 returns the uint64 held by v, which must be of kind KindUint64.
+func (v Value) Uint64() uint64 {
+	v.mustBe(KindUint64)
+	return v.num
+}
+
+// Time returns the time.Time held by v, which must be of kind KindTime.
+func (v Value) Time() time.Time {
+	v.mustBe(KindTime)
+	if loc, ok := v.any.(timeLocation); ok {
+		return time.Unix(0, int64(v.num)).In(loc)
+	}
+	t, _ := v.any.(timeTime)
+	return time.Time(t)
+}
+
+// String returns the string held by v if it is of kind KindString, or the
+// representation of v formatted with the %v verb otherwise.
+func (v Value) String() string {
+	if v.Kind() == KindString {
+		return v.str()
+	}
+	return string(v.append(nil))
+}
+
+// Format satisfies the fmt.Formatter interface.
+func (v Value) Format(f fmt.State, verb rune) {
+	if !hasFormatOptions(f) {
+		k := v.Kind()
+
+		switch {
+		case k == KindString && (verb == 'v' || verb == 's'):
+			io.WriteString(f, v.str())
+			return
+
+		case k == KindAny && verb == 'v':
+			fmt.Fprint(f, v.any)
+			return
+
+		case verb == 'v',
+			verb == 'd' && (k == KindInt64 || k == KindUint64),
+			verb == 't' && k == KindBool,
+			verb == 'q' && k == KindString:
+			// The buffer is pooled because it would escape to the heap when
+			// passed to f.Write.
+			buf := formatBufferPool.Get().(*formatBuffer)
+			if verb == 'q' {
+				buf.b = strconv.AppendQuote(buf.b[:0], v.str())
+			} else {
+				buf.b = v.append(buf.b[:0])
+			}
+			f.Write(buf.b)
+			formatBufferPool.Put(buf)
+			return
+		}
+	}
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), v.Any())
+}
+
+// Valuer is implemented by event argument values that are resolved lazily.
+//
+// Loggers keep valuers unevaluated in the events they generate, the handlers of
+// the events sub-packages resolve them only when encoding the events, so the
+// cost of producing the values isn't paid for events that were discarded, and
+// Event.Clone resolves them before copying the values.
+//
+// Valuers are not inspected when looking for errors in the arguments of events:
+// the errors they produce don't affect the severity of events nor trigger stack
+// captures, and handlers encode them like other values (not in the sections
+// dedicated to errors). Errors should be passed directly as arguments.
+type Valuer interface {
+	EventValue() interface{}
+}
+
+// LazyValue is an implementation of the Valuer interface which calls the
+// function to produce the value of an argument.
+//
+// LazyValue implements fmt.Formatter, the values it produces are formatted as
+// if they were passed directly to the formatting functions.
+type LazyValue func() interface{}
+
+// Lazy returns a LazyValue calling f to produce the value of an argument.
+func Lazy(f func() interface{}) LazyValue {
+	return LazyValue(f)
+}
+
+// EventValue satisfies the Valuer interface.
+func (f LazyValue) EventValue() interface{} {
+	return f()
+}
+
+// Format satisfies the fmt.Formatter interface.
+func (f LazyValue) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), Resolve(f))
+}
+
+// Resolve returns the value that v represents. If v is a Valuer, its
+// EventValue method is called repeatedly until it returns a value that is not
+// a Valuer. Other values, including values of type Value, are returned as-is.
+func Resolve(v interface{}) interface{} {
+	for i := 0; i < maxResolveDepth; i++ {
+		x, ok := v.(Valuer)
+		if !ok {
+			return v
+		}
+		v = x.EventValue()
+	}
+	if _, ok := v.(Valuer); ok {
+		return fmt.Sprintf("!EVENTS: too many levels of Valuer (%T)", v)
+	}
+	return v
+}
+
+// maxResolveDepth limits the number of times Resolve calls the EventValue
+// method, protecting programs from valuers that return themselves.
+const maxResolveDepth = 100
+
+type formatBuffer struct{ b []byte }
+
+var formatBufferPool = sync.Pool{
+	New: func() interface{} { return &formatBuffer{make([]byte, 0, 64)} },
+}
+
+// append writes the representation of v formatted with the %v verb to b.
+func (v Value) append(b []byte) []byte {
+	switch v.Kind() {
+	case KindBool:
+		return strconv.AppendBool(b, v.num != 0)
+	case KindDuration:
+		return append(b, time.Duration(v.num).String()...)
+	case KindFloat64:
+		return strconv.AppendFloat(b, math.Float64frombits(v.num), 'g', -1, 64)
+	case KindInt64:
+		return strconv.AppendInt(b, int64(v.num), 10)
+	case KindString:
+		return append(b, v.str()...)
+	case KindTime:
+		return append(b, v.Time().String()...)
+	case KindUint64:
+		return strconv.AppendUint(b, v.num, 10)
+	default:
+		return fmt.Append(b, v.any)
+	}
+}
+
+func (v Value) mustBe(k Kind) {
+	if v.Kind() != k {
+		panic("events: value of kind " + v.Kind().String() + " used as " + k.String())
+	}
+}
+
+func hasFormatOptions(f fmt.State) bool {
+	_, hasWidth := f.Width()
+	_, hasPrec := f.Precision()
+	return hasWidth || hasPrec || f.Flag('+') || f.Flag('-') || f.Flag('#') || f.Flag(' ') || f.Flag('0')
+}
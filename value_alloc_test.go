@@ -0,0 +1,46 @@
+//go:build !race
+// +build !race
+
+package events
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestValueLog(t *testing.T) {
+	v := Int(42)
+	msg := ""
+	l := NewLogger(HandlerFunc(func(e *Event) { msg = e.Message }))
+	l.Log("answer = %{answer}d", &v)
+
+	if msg != "answer = 42" {
+		t.Error("bad message:", msg)
+	}
+
+	base := testing.AllocsPerRun(100, func() { l.Log("answer") })
+
+	if n := testing.AllocsPerRun(100, func() { l.Log("answer = %{answer}d", &v) }); n > base {
+		t.Errorf("logging a pointer to a value must not allocate: %g > %g", n, base)
+	}
+}
+
+func TestValueConstructors(t *testing.T) {
+	now := time.Now()
+	s := "Luke"
+	var v Value
+
+	if n := testing.AllocsPerRun(100, func() {
+		v = Int(1000)
+		v = String(s)
+		v = Time(now)
+		v = Float64(0.5)
+	}); n != 0 {
+		t.Errorf("creating values must not allocate: %g", n)
+	}
+
+	if size := unsafe.Sizeof(v); size > 24 {
+		t.Errorf("values must fit in 24 bytes: %d", size)
+	}
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValue(t *testing.T) {
+	now := time.Date(2017, 1, 1, 23, 42, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		value Value
+		kind  Kind
+		any   interface{}
+	}{
+		{Int(42), KindInt64, 42},
+		{Int64(-1), KindInt64, int64(-1)},
+		{Uint64(1 << 63), KindUint64, uint64(1 << 63)},
+		{Float64(0.5), KindFloat64, 0.5},
+		{String("Luke"), KindString, "Luke"},
+		{Bool(true), KindBool, true},
+		{Duration(time.Second), KindDuration, time.Second},
+		{Time(now), KindTime, now},
+		{Time(time.Time{}), KindTime, time.Time{}},
+		{Any([]int{1, 2}), KindAny, []int{1, 2}},
+		{Any(42), KindInt64, 42},
+		{Any(String("Han")), KindString, "Han"},
+		{Value{}, KindAny, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.any), func(t *testing.T) {
+			if k := test.value.Kind(); k != test.kind {
+				t.Error("bad kind:", k)
+			}
+			if v := test.value.Any(); !reflect.DeepEqual(v, test.any) {
+				t.Errorf("bad value: %#v", v)
+			}
+			if s, ref := fmt.Sprint(test.value), fmt.Sprint(test.any); s != ref {
+				t.Errorf("bad representation: %q != %q", s, ref)
+			}
+		})
+	}
+}
+
+func TestValueFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		output string
+	}{
+		{"%v", Int(42), "42"},
+		{"%d", Int(42), "42"},
+		{"%x", Int(42), "2a"},
+		{"%5d", Int(42), "   42"},
+		{"%s", String("Luke"), "Luke"},
+		{"%q", String("Luke"), `"Luke"`},
+		{"%-6s|", String("Luke"), "Luke  |"},
+		{"%t", Bool(false), "false"},
+		{"%v", Duration(time.Millisecond), "1ms"},
+		{"%.2f", Float64(0.125), "0.12"},
+		{"%v", Any(struct{ A int }{1}), "{1}"},
+		{"%+v", Any(struct{ A int }{1}), "{A:1}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if s := fmt.Sprintf(test.format, test.value); s != test.output {
+				t.Errorf("%q != %q", s, test.output)
+			}
+		})
+	}
+}
+
+func TestValueClone(t *testing.T) {
+	v := Int(42)
+	e := (&Event{Args: Args{{"a", String("Luke")}, {"b", &v}, {"c", nil}}}).Clone()
+
+	if !reflect.DeepEqual(e.Args, Args{{"a", "Luke"}, {"b", 42}, {"c", nil}}) {
+		t.Errorf("%#v", e.Args)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	lazy := Lazy(func() interface{} {
+		calls++
+		return "Luke"
+	})
+
+	var event *Event
+	l := NewLogger(HandlerFunc(func(e *Event) { event = e.Clone() }))
+	l.EnableDebug = false
+	l.Debug("hello %{name}s", lazy) // debug disabled, never resolved
+	l.Log("hello", Args{{"name", lazy}})
+
+	if calls != 1 {
+		t.Error("the lazy value must be resolved once when cloning the event:", calls)
+	}
+
+	if !reflect.DeepEqual(event.Args, Args{{"name", "Luke"}}) {
+		t.Errorf("%#v", event.Args)
+	}
+
+	if s := fmt.Sprintf("%q", lazy); s != `"Luke"` {
+		t.Error("bad format:", s)
+	}
+}
+
+type selfValuer struct{}
+
+func (v selfValuer) EventValue() interface{} { return v }
+
+func TestResolve(t *testing.T) {
+	nested := Lazy(func() interface{} {
+		return Lazy(func() interface{} { return 42 })
+	})
+
+	if v := Resolve(nested); v != 42 {
+		t.Error("nested valuers must be resolved:", v)
+	}
+
+	if v := Resolve(Int(42)); v != Int(42) {
+		t.Error("values must be returned as-is:", v)
+	}
+
+	if _, ok := Resolve(selfValuer{}).(string); !ok {
+		t.Error("recursive valuers must be resolved to an error message")
+	}
+}